@@ -0,0 +1,273 @@
+package secp256k1
+
+import (
+	"errors"
+)
+
+const (
+	// Length of elements byte representations
+	LenPubKeyHash int = 20
+)
+
+// ConfidentialAddressType is the type of the output script of a
+// confidential address
+type ConfidentialAddressType int
+
+const (
+	// ConfidentialP2PKH is a legacy base58 pay to public key hash address
+	ConfidentialP2PKH ConfidentialAddressType = iota
+	// ConfidentialP2SH is a legacy base58 pay to script hash address
+	ConfidentialP2SH
+	// ConfidentialSegwit is a blech32 or blech32m segwit address
+	ConfidentialSegwit
+)
+
+var (
+	ErrorAddressNetwork         = errors.New("address does not belong to a known network")
+	ErrorAddressEncoding        = errors.New("invalid confidential address encoding")
+	ErrorAddressType            = errors.New("unknown confidential address type")
+	ErrorAddressHashSize        = errors.New("address hash must be exactly 20 bytes")
+	ErrorWitnessVersion         = errors.New("witness version must be between 0 and 16")
+	ErrorWitnessProgramSize     = errors.New("invalid witness program length")
+	ErrorWitnessVersionEncoding = errors.New("witness version 0 requires blech32, later versions blech32m")
+)
+
+// Network holds the prefixes of the addresses of an Elements chain
+type Network struct {
+	Name string
+	// PubKeyHashPrefix and ScriptHashPrefix are the version bytes of base58
+	// P2PKH and P2SH addresses
+	PubKeyHashPrefix byte
+	ScriptHashPrefix byte
+	// ConfidentialPrefix is the version byte prefixing base58 confidential
+	// addresses
+	ConfidentialPrefix byte
+	// Blech32HRP is the human readable part of blech32 confidential segwit
+	// addresses
+	Blech32HRP string
+}
+
+var (
+	// Liquid is the Liquid network
+	Liquid = Network{
+		Name:               "liquid",
+		PubKeyHashPrefix:   57,
+		ScriptHashPrefix:   39,
+		ConfidentialPrefix: 12,
+		Blech32HRP:         "lq",
+	}
+	// LiquidTestnet is the Liquid test network
+	LiquidTestnet = Network{
+		Name:               "liquidtestnet",
+		PubKeyHashPrefix:   36,
+		ScriptHashPrefix:   19,
+		ConfidentialPrefix: 23,
+		Blech32HRP:         "tlq",
+	}
+	// LiquidRegtest is the default regtest network of Elements
+	LiquidRegtest = Network{
+		Name:               "elementsregtest",
+		PubKeyHashPrefix:   235,
+		ScriptHashPrefix:   75,
+		ConfidentialPrefix: 4,
+		Blech32HRP:         "el",
+	}
+
+	// networks are the networks recognized by ConfidentialAddressDecode
+	networks = []*Network{&Liquid, &LiquidTestnet, &LiquidRegtest}
+)
+
+// ConfidentialAddress is an Elements confidential address: an output script
+// along with the blinding public key the outputs paying to it are blinded
+// to. See MasterBlindingKey to derive the blinding key of a script.
+type ConfidentialAddress struct {
+	Network     *Network
+	Type        ConfidentialAddressType
+	BlindingKey *PublicKey
+	// WitnessVersion is the witness version of a segwit address
+	WitnessVersion int
+	// Program is the witness program of a segwit address, the public key
+	// or script hash of a base58 address
+	Program []byte
+}
+
+// ConfidentialAddressEncode encodes a confidential address: segwit
+// addresses are encoded with blech32 for witness version 0 and blech32m for
+// later versions, P2PKH and P2SH ones with base58check.
+func ConfidentialAddressEncode(address *ConfidentialAddress) (string, error) {
+	if address.Network == nil {
+		return "", newError("ConfidentialAddressEncode", 0, ErrorAddressNetwork)
+	}
+	if address.BlindingKey == nil {
+		return "", newError("ConfidentialAddressEncode", 0, ErrorPublicKeyNil)
+	}
+	blindingKey := address.BlindingKey.Bytes(true)
+
+	switch address.Type {
+	case ConfidentialP2PKH, ConfidentialP2SH:
+		if len(address.Program) != LenPubKeyHash {
+			return "", newError("ConfidentialAddressEncode", 0, ErrorAddressHashSize)
+		}
+		prefix := address.Network.PubKeyHashPrefix
+		if address.Type == ConfidentialP2SH {
+			prefix = address.Network.ScriptHashPrefix
+		}
+		payload := make([]byte, 0, 2+LenCompressed+LenPubKeyHash)
+		payload = append(payload, address.Network.ConfidentialPrefix, prefix)
+		payload = append(payload, blindingKey...)
+		payload = append(payload, address.Program...)
+		return base58CheckEncode(payload), nil
+
+	case ConfidentialSegwit:
+		if err := checkWitnessProgram(address.WitnessVersion, address.Program); err != nil {
+			return "", newError("ConfidentialAddressEncode", 0, err)
+		}
+		program, _ := convertBits(append(blindingKey, address.Program...), 8, 5, true)
+		data := append([]byte{byte(address.WitnessVersion)}, program...)
+		encoding := blech32m
+		if address.WitnessVersion == 0 {
+			encoding = blech32
+		}
+		return blech32Encode(address.Network.Blech32HRP, data, encoding), nil
+	}
+	return "", newError("ConfidentialAddressEncode", 0, ErrorAddressType)
+}
+
+// ConfidentialAddressDecode decodes a confidential address of one of the
+// networks Liquid, LiquidTestnet and LiquidRegtest. The blinding key must be
+// a valid compressed public key.
+func ConfidentialAddressDecode(ctx *Context, address string) (*ConfidentialAddress, error) {
+	if hrp, data, encoding, err := blech32Decode(address); err == nil {
+		return decodeConfidentialSegwit(ctx, hrp, data, encoding)
+	}
+
+	payload, err := base58CheckDecode(address)
+	if err != nil {
+		return nil, newError("ConfidentialAddressDecode", 0, ErrorAddressEncoding)
+	}
+	if len(payload) != 2+LenCompressed+LenPubKeyHash {
+		return nil, newError("ConfidentialAddressDecode", 0, ErrorAddressEncoding)
+	}
+	for _, network := range networks {
+		if payload[0] != network.ConfidentialPrefix {
+			continue
+		}
+		var addressType ConfidentialAddressType
+		switch payload[1] {
+		case network.PubKeyHashPrefix:
+			addressType = ConfidentialP2PKH
+		case network.ScriptHashPrefix:
+			addressType = ConfidentialP2SH
+		default:
+			continue
+		}
+		blindingKey, err := parseBlindingKey(ctx, payload[2:2+LenCompressed])
+		if err != nil {
+			return nil, err
+		}
+		return &ConfidentialAddress{
+			Network:     network,
+			Type:        addressType,
+			BlindingKey: blindingKey,
+			Program:     payload[2+LenCompressed:],
+		}, nil
+	}
+	return nil, newError("ConfidentialAddressDecode", 0, ErrorAddressNetwork)
+}
+
+func decodeConfidentialSegwit(ctx *Context, hrp string, data []byte, encoding blech32Encoding) (*ConfidentialAddress, error) {
+	var network *Network
+	for _, n := range networks {
+		if n.Blech32HRP == hrp {
+			network = n
+		}
+	}
+	if network == nil {
+		return nil, newError("ConfidentialAddressDecode", 0, ErrorAddressNetwork)
+	}
+	if len(data) < 1 {
+		return nil, newError("ConfidentialAddressDecode", 0, ErrorAddressEncoding)
+	}
+
+	version := int(data[0])
+	program, ok := convertBits(data[1:], 5, 8, false)
+	if !ok || len(program) < LenCompressed {
+		return nil, newError("ConfidentialAddressDecode", 0, ErrorAddressEncoding)
+	}
+	blindingKey, witnessProgram := program[:LenCompressed], program[LenCompressed:]
+	if err := checkWitnessProgram(version, witnessProgram); err != nil {
+		return nil, newError("ConfidentialAddressDecode", 0, err)
+	}
+	if (version == 0) != (encoding == blech32) {
+		return nil, newError("ConfidentialAddressDecode", 0, ErrorWitnessVersionEncoding)
+	}
+
+	pk, err := parseBlindingKey(ctx, blindingKey)
+	if err != nil {
+		return nil, err
+	}
+	return &ConfidentialAddress{
+		Network:        network,
+		Type:           ConfidentialSegwit,
+		BlindingKey:    pk,
+		WitnessVersion: version,
+		Program:        witnessProgram,
+	}, nil
+}
+
+// parseBlindingKey parses the blinding key of an address, which is always
+// compressed
+func parseBlindingKey(ctx *Context, key []byte) (*PublicKey, error) {
+	if len(key) != LenCompressed {
+		return nil, newError("ConfidentialAddressDecode", 0, ErrorPublicKeySize)
+	}
+	_, pk, err := EcPubkeyParseStrict(ctx, key)
+	return pk, err
+}
+
+// checkWitnessProgram applies the rules of BIP141 to the witness version
+// and program of a segwit address
+func checkWitnessProgram(version int, program []byte) error {
+	if version < 0 || version > 16 {
+		return ErrorWitnessVersion
+	}
+	if len(program) < 2 || len(program) > 40 {
+		return ErrorWitnessProgramSize
+	}
+	if version == 0 && len(program) != 20 && len(program) != 32 {
+		return ErrorWitnessProgramSize
+	}
+	return nil
+}
+
+// String returns the encoding of the address, or an empty string if the
+// address is invalid
+func (a *ConfidentialAddress) String() string {
+	s, _ := ConfidentialAddressEncode(a)
+	return s
+}
+
+// ScriptPubKey returns the output script the address pays to, whose
+// blinding key can be derived with MasterBlindingKey.BlindingPublicKey
+func (a *ConfidentialAddress) ScriptPubKey() []byte {
+	switch a.Type {
+	case ConfidentialP2PKH:
+		script := append([]byte{opDup, opHash160, byte(len(a.Program))}, a.Program...)
+		return append(script, opEqualVerify, opCheckSig)
+	case ConfidentialP2SH:
+		script := append([]byte{opHash160, byte(len(a.Program))}, a.Program...)
+		return append(script, opEqual)
+	default:
+		version := byte(0)
+		if a.WitnessVersion > 0 {
+			version = byte(op1 + a.WitnessVersion - 1)
+		}
+		return append([]byte{version, byte(len(a.Program))}, a.Program...)
+	}
+}
+
+// Equal reports whether two valid addresses have the same encoding
+func (a *ConfidentialAddress) Equal(other *ConfidentialAddress) bool {
+	encoded := a.String()
+	return encoded != "" && encoded == other.String()
+}
@@ -0,0 +1,259 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfidentialAddressDecode(t *testing.T) {
+	ctx, _ := ContextCreate(ContextNone)
+	defer ContextDestroy(ctx)
+
+	tests := []struct {
+		address        string
+		network        *Network
+		witnessVersion int
+		programLen     int
+	}{
+		{
+			"el1qqw3e3mk4ng3ks43mh54udznuekaadh9lgwef3mwgzrfzakmdwcvqqve2xzutyaf7vjcap67f28q90uxec2ve95g3rpu5crapcmfr2l9xl5jzazvcpysz",
+			&LiquidRegtest, 0, 32,
+		},
+		{
+			"lq1qqvxk052kf3qtkxmrakx50a9gc3smqad2ync54hzntjt980kfej9kkfe0247rp5h4yzmdftsahhw64uy8pzfe7cpg4fgykm7cv",
+			&Liquid, 0, 20,
+		},
+	}
+
+	for _, v := range tests {
+		for _, s := range []string{v.address, strings.ToUpper(v.address)} {
+			address, err := ConfidentialAddressDecode(ctx, s)
+			if !assert.NoError(t, err, s) {
+				continue
+			}
+			assert.Equal(t, v.network, address.Network)
+			assert.Equal(t, ConfidentialSegwit, address.Type)
+			assert.Equal(t, v.witnessVersion, address.WitnessVersion)
+			assert.Equal(t, v.programLen, len(address.Program))
+			assert.Equal(t, v.address, address.String())
+		}
+	}
+}
+
+func TestConfidentialAddressRoundTrip(t *testing.T) {
+	ctx, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(ctx)
+
+	blindingKey := newTestBlindingKey(ctx)
+
+	addresses := []ConfidentialAddress{
+		{Type: ConfidentialP2PKH, Program: make([]byte, 20)},
+		{Type: ConfidentialP2SH, Program: make([]byte, 20)},
+		{Type: ConfidentialSegwit, WitnessVersion: 0, Program: make([]byte, 20)},
+		{Type: ConfidentialSegwit, WitnessVersion: 0, Program: make([]byte, 32)},
+		{Type: ConfidentialSegwit, WitnessVersion: 1, Program: make([]byte, 32)},
+		{Type: ConfidentialSegwit, WitnessVersion: 16, Program: make([]byte, 2)},
+	}
+	for _, network := range networks {
+		for _, a := range addresses {
+			a.Network = network
+			a.BlindingKey = blindingKey
+			for i := range a.Program {
+				a.Program[i] = byte(i + 1)
+			}
+
+			encoded, err := ConfidentialAddressEncode(&a)
+			if !assert.NoError(t, err) {
+				continue
+			}
+			decoded, err := ConfidentialAddressDecode(ctx, encoded)
+			if !assert.NoError(t, err, encoded) {
+				continue
+			}
+			assert.Equal(t, &a, decoded)
+			assert.True(t, a.Equal(decoded))
+			assert.Equal(t, encoded, decoded.String())
+		}
+	}
+}
+
+func TestConfidentialAddressPrefixes(t *testing.T) {
+	ctx, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(ctx)
+
+	blindingKey := newTestBlindingKey(ctx)
+
+	tests := []struct {
+		network *Network
+		p2pkh   string
+		p2sh    string
+		segwit  string
+	}{
+		{&Liquid, "VT", "VJ", "lq1"},
+		{&LiquidTestnet, "vt", "vj", "tlq1"},
+		{&LiquidRegtest, "CTE", "Azp", "el1"},
+	}
+	for _, v := range tests {
+		address := ConfidentialAddress{
+			Network:     v.network,
+			BlindingKey: blindingKey,
+			Program:     make([]byte, 20),
+		}
+		address.Type = ConfidentialP2PKH
+		assert.True(t, strings.HasPrefix(address.String(), v.p2pkh), address.String())
+		address.Type = ConfidentialP2SH
+		assert.True(t, strings.HasPrefix(address.String(), v.p2sh), address.String())
+		address.Type = ConfidentialSegwit
+		assert.True(t, strings.HasPrefix(address.String(), v.segwit), address.String())
+	}
+}
+
+func TestConfidentialAddressScriptPubKey(t *testing.T) {
+	ctx, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(ctx)
+
+	blindingKey := newTestBlindingKey(ctx)
+	hash := make([]byte, 20)
+	hash[0] = 0xab
+
+	tests := []struct {
+		address ConfidentialAddress
+		script  string
+	}{
+		{
+			ConfidentialAddress{Type: ConfidentialP2PKH, Program: hash},
+			"76a914ab0000000000000000000000000000000000000088ac",
+		},
+		{
+			ConfidentialAddress{Type: ConfidentialP2SH, Program: hash},
+			"a914ab0000000000000000000000000000000000000087",
+		},
+		{
+			ConfidentialAddress{Type: ConfidentialSegwit, Program: hash},
+			"0014ab00000000000000000000000000000000000000",
+		},
+		{
+			ConfidentialAddress{Type: ConfidentialSegwit, WitnessVersion: 1, Program: hash[:2]},
+			"5102ab00",
+		},
+		{
+			ConfidentialAddress{Type: ConfidentialSegwit, WitnessVersion: 16, Program: hash[:2]},
+			"6002ab00",
+		},
+	}
+	for _, v := range tests {
+		assert.Equal(t, v.script, hex.EncodeToString(v.address.ScriptPubKey()))
+	}
+
+	// the blinding key of an address generated by a SLIP-77 wallet is the
+	// one of its output script
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	masterBlindingKey, _ := MasterBlindingKeyFromSeed(seed)
+	address := ConfidentialAddress{
+		Network:        &Liquid,
+		Type:           ConfidentialSegwit,
+		WitnessVersion: 0,
+		Program:        hash,
+	}
+	address.BlindingKey, _ = masterBlindingKey.BlindingPublicKey(address.ScriptPubKey())
+
+	decoded, err := ConfidentialAddressDecode(ctx, address.String())
+	assert.NoError(t, err)
+	expected, _ := masterBlindingKey.BlindingPublicKey(decoded.ScriptPubKey())
+	assert.True(t, expected.Equal(decoded.BlindingKey))
+	assert.NotEqual(t, blindingKey.String(), decoded.BlindingKey.String())
+}
+
+func TestConfidentialAddressEncodeInvalid(t *testing.T) {
+	ctx, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(ctx)
+
+	blindingKey := newTestBlindingKey(ctx)
+
+	tests := []struct {
+		address ConfidentialAddress
+		err     error
+	}{
+		{ConfidentialAddress{BlindingKey: blindingKey, Program: make([]byte, 20)}, ErrorAddressNetwork},
+		{ConfidentialAddress{Network: &Liquid, Program: make([]byte, 20)}, ErrorPublicKeyNil},
+		{ConfidentialAddress{Network: &Liquid, BlindingKey: blindingKey, Program: make([]byte, 32)}, ErrorAddressHashSize},
+		{ConfidentialAddress{Network: &Liquid, BlindingKey: blindingKey, Type: 3, Program: make([]byte, 20)}, ErrorAddressType},
+		{ConfidentialAddress{Network: &Liquid, BlindingKey: blindingKey, Type: ConfidentialSegwit, WitnessVersion: 17, Program: make([]byte, 32)}, ErrorWitnessVersion},
+		{ConfidentialAddress{Network: &Liquid, BlindingKey: blindingKey, Type: ConfidentialSegwit, Program: make([]byte, 21)}, ErrorWitnessProgramSize},
+		{ConfidentialAddress{Network: &Liquid, BlindingKey: blindingKey, Type: ConfidentialSegwit, WitnessVersion: 1, Program: make([]byte, 41)}, ErrorWitnessProgramSize},
+		{ConfidentialAddress{Network: &Liquid, BlindingKey: blindingKey, Type: ConfidentialSegwit, WitnessVersion: 1, Program: make([]byte, 1)}, ErrorWitnessProgramSize},
+	}
+	for i, v := range tests {
+		_, err := ConfidentialAddressEncode(&v.address)
+		assert.True(t, errors.Is(err, v.err), "%d: %v", i, err)
+		assert.Equal(t, "", v.address.String())
+		assert.False(t, v.address.Equal(&v.address))
+	}
+}
+
+func TestConfidentialAddressDecodeInvalid(t *testing.T) {
+	ctx, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(ctx)
+
+	blindingKey := newTestBlindingKey(ctx)
+	key := blindingKey.Bytes(true)
+
+	segwit := func(hrp string, version byte, program []byte, encoding blech32Encoding) string {
+		data, _ := convertBits(program, 8, 5, true)
+		return blech32Encode(hrp, append([]byte{version}, data...), encoding)
+	}
+	withKey := func(program []byte) []byte {
+		return append(append([]byte{}, key...), program...)
+	}
+	base58 := func(confidentialPrefix, prefix byte, key, hash []byte) string {
+		payload := append([]byte{confidentialPrefix, prefix}, key...)
+		return base58CheckEncode(append(payload, hash...))
+	}
+	invalidKey := append([]byte{0x04}, key[1:]...)
+	offCurveKey := append([]byte{0x02}, make([]byte, 32)...)
+	valid := segwit("lq", 0, withKey(make([]byte, 20)), blech32)
+
+	tests := []struct {
+		address string
+		err     error
+	}{
+		{"", ErrorAddressEncoding},
+		{"not an address", ErrorAddressEncoding},
+		{valid[:len(valid)-1] + "q", ErrorAddressEncoding},
+		{segwit("bc", 0, withKey(make([]byte, 20)), blech32), ErrorAddressNetwork},
+		{segwit("lq", 0, withKey(make([]byte, 20)), blech32m), ErrorWitnessVersionEncoding},
+		{segwit("lq", 1, withKey(make([]byte, 32)), blech32), ErrorWitnessVersionEncoding},
+		{segwit("lq", 0, withKey(make([]byte, 21)), blech32), ErrorWitnessProgramSize},
+		{segwit("lq", 1, withKey(make([]byte, 41)), blech32m), ErrorWitnessProgramSize},
+		{segwit("lq", 17, withKey(make([]byte, 32)), blech32m), ErrorWitnessVersion},
+		{segwit("lq", 0, key[:20], blech32), ErrorAddressEncoding},
+		{blech32Encode("lq", nil, blech32), ErrorAddressEncoding},
+		{segwit("lq", 0, append(invalidKey, make([]byte, 20)...), blech32), ErrorPublicKeyParse},
+		{segwit("lq", 0, append(offCurveKey, make([]byte, 20)...), blech32), ErrorPublicKeyParse},
+		{base58(Liquid.ConfidentialPrefix, Liquid.PubKeyHashPrefix, key, make([]byte, 21)), ErrorAddressEncoding},
+		{base58(Liquid.ConfidentialPrefix, 0, key, make([]byte, 20)), ErrorAddressNetwork},
+		{base58(0, Liquid.PubKeyHashPrefix, key, make([]byte, 20)), ErrorAddressNetwork},
+		{base58(Liquid.ConfidentialPrefix, Liquid.PubKeyHashPrefix, invalidKey, make([]byte, 20)), ErrorPublicKeyParse},
+	}
+	for i, v := range tests {
+		_, err := ConfidentialAddressDecode(ctx, v.address)
+		assert.True(t, errors.Is(err, v.err), "%d: %v", i, err)
+	}
+
+	// blinding keys are only ever compressed
+	for _, k := range [][]byte{nil, key[:1], blindingKey.Bytes(false)} {
+		_, err := parseBlindingKey(ctx, k)
+		assert.True(t, errors.Is(err, ErrorPublicKeySize), len(k))
+	}
+}
+
+func newTestBlindingKey(ctx *Context) *PublicKey {
+	seckey := make([]byte, 32)
+	seckey[31] = 3
+	_, pk, _ := EcPubkeyCreate(ctx, seckey)
+	return pk
+}
@@ -0,0 +1,105 @@
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// base58Alphabet is the alphabet used by Bitcoin and Elements
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	ErrorBase58Encoding = errors.New("invalid base58 encoding")
+	ErrorBase58Checksum = errors.New("invalid base58 checksum")
+)
+
+var (
+	base58Radix   = big.NewInt(58)
+	base58Decoded [256]int8
+)
+
+func init() {
+	for i := range base58Decoded {
+		base58Decoded[i] = -1
+	}
+	for i := 0; i < len(base58Alphabet); i++ {
+		base58Decoded[base58Alphabet[i]] = int8(i)
+	}
+}
+
+// base58Encode encodes b in base58, every leading zero byte being encoded
+// as a leading '1'.
+func base58Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+
+	n := new(big.Int).SetBytes(b)
+	mod := new(big.Int)
+	out := make([]byte, 0, len(b)*138/100+1)
+	for n.Sign() > 0 {
+		n.DivMod(n, base58Radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < zeros; i++ {
+		out = append(out, base58Alphabet[0])
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// base58Decode decodes a base58 string encoded by base58Encode.
+func base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	n := new(big.Int)
+	digit := new(big.Int)
+	for i := zeros; i < len(s); i++ {
+		d := base58Decoded[s[i]]
+		if d < 0 {
+			return nil, ErrorBase58Encoding
+		}
+		n.Mul(n, base58Radix)
+		n.Add(n, digit.SetInt64(int64(d)))
+	}
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// base58CheckEncode encodes payload in base58 followed by the first 4 bytes
+// of its double SHA256 as checksum.
+func base58CheckEncode(payload []byte) string {
+	b := make([]byte, 0, len(payload)+4)
+	b = append(b, payload...)
+	return base58Encode(append(b, base58Checksum(payload)...))
+}
+
+// base58CheckDecode decodes a string encoded by base58CheckEncode and
+// returns the payload after verifying its checksum.
+func base58CheckDecode(s string) ([]byte, error) {
+	b, err := base58Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) < 4 {
+		return nil, ErrorBase58Checksum
+	}
+	payload := b[:len(b)-4]
+	if !bytes.Equal(base58Checksum(payload), b[len(b)-4:]) {
+		return nil, ErrorBase58Checksum
+	}
+	return payload, nil
+}
+
+func base58Checksum(payload []byte) []byte {
+	first := sha256.Sum256(payload)
+	second := sha256.Sum256(first[:])
+	return second[:4]
+}
@@ -0,0 +1,50 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase58(t *testing.T) {
+	tests := []struct {
+		hex     string
+		base58  string
+		checked string
+	}{
+		{"", "", "3QJmnh"},
+		{"00", "1", "1Wh4bh"},
+		{"0000", "11", "112edB6q"},
+		{"61", "2g", "C2dGTwc"},
+		{"626262", "a3gV", "4jF5uERJAK"},
+		{"0000287fb4cd", "11233QC4", "117mtbcoTR2qp"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L", "13REmUhe2ckUKy1FvM7AMCdtyYq831yxM3QeyEu4"},
+	}
+
+	for _, tt := range tests {
+		b, _ := hex.DecodeString(tt.hex)
+		assert.Equal(t, tt.base58, base58Encode(b))
+		assert.Equal(t, tt.checked, base58CheckEncode(b))
+
+		decoded, err := base58Decode(tt.base58)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.hex, hex.EncodeToString(decoded))
+
+		decoded, err = base58CheckDecode(tt.checked)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.hex, hex.EncodeToString(decoded))
+	}
+}
+
+func TestBase58Invalid(t *testing.T) {
+	for _, s := range []string{"0", "O", "I", "l", "3QJm h", "é"} {
+		_, err := base58Decode(s)
+		assert.Equal(t, ErrorBase58Encoding, err, s)
+	}
+
+	for _, s := range []string{"", "1", "3QJmni", "4jF5uERJAL"} {
+		_, err := base58CheckDecode(s)
+		assert.Equal(t, ErrorBase58Checksum, err, s)
+	}
+}
@@ -0,0 +1,444 @@
+package secp256k1
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HardenedKeyStart is the index of the first hardened child key
+	HardenedKeyStart uint32 = 0x80000000
+
+	// MinSeedBytes and MaxSeedBytes bound the length of the seed of a
+	// master key, as recommended by BIP32
+	MinSeedBytes = 16
+	MaxSeedBytes = 64
+
+	// Length of elements byte representations
+	LenChainCode   int = 32
+	LenFingerprint int = 4
+	LenExtendedKey int = 78
+)
+
+// masterKeyHMACKey is the key of the HMAC-SHA512 deriving a master key from
+// a seed
+var masterKeyHMACKey = []byte("Bitcoin seed")
+
+var (
+	ErrorSeedSize              = errors.New("seed must be between 16 and 64 bytes")
+	ErrorDerivationPath        = errors.New("invalid derivation path")
+	ErrorHardenedFromPublic    = errors.New("cannot derive a hardened child key from a public key")
+	ErrorDeriveChildKey        = errors.New("invalid child key, the next index must be used")
+	ErrorDerivationDepth       = errors.New("maximum derivation depth reached")
+	ErrorExtendedKeyEncoding   = errors.New("invalid extended key encoding")
+	ErrorExtendedKeyVersion    = errors.New("unknown extended key version")
+	ErrorExtendedKeyNotPrivate = errors.New("extended key is not private")
+)
+
+// ExtendedKeyVersion holds the version bytes prefixing the serialization of
+// private and public extended keys, which select the network they are
+// meant for.
+type ExtendedKeyVersion struct {
+	Private [4]byte
+	Public  [4]byte
+}
+
+var (
+	// MainnetExtendedKeyVersion serializes keys as xprv and xpub, the
+	// versions also used by Liquid
+	MainnetExtendedKeyVersion = ExtendedKeyVersion{
+		Private: [4]byte{0x04, 0x88, 0xad, 0xe4},
+		Public:  [4]byte{0x04, 0x88, 0xb2, 0x1e},
+	}
+	// TestnetExtendedKeyVersion serializes keys as tprv and tpub
+	TestnetExtendedKeyVersion = ExtendedKeyVersion{
+		Private: [4]byte{0x04, 0x35, 0x83, 0x94},
+		Public:  [4]byte{0x04, 0x35, 0x87, 0xcf},
+	}
+
+	// extendedKeyVersions are the versions recognized by ExtendedKeyParse
+	extendedKeyVersions = []ExtendedKeyVersion{
+		MainnetExtendedKeyVersion,
+		TestnetExtendedKeyVersion,
+	}
+)
+
+// ExtendedKey is a BIP32 extended key: a private or public key along with
+// the chain code and the position in the tree needed to derive its
+// children. Deriving private keys requires a context initialized for
+// signing, while deriving public keys requires one initialized for
+// verification.
+type ExtendedKey struct {
+	version           ExtendedKeyVersion
+	depth             uint8
+	parentFingerprint [4]byte
+	childNumber       uint32
+	chainCode         [32]byte
+	privateKey        *PrivateKey
+	publicKey         *PublicKey
+}
+
+// NewMasterKey derives the master extended private key of the tree defined
+// by seed, which must be between 16 and 64 bytes.
+func NewMasterKey(ctx *Context, seed []byte, version ExtendedKeyVersion) (*ExtendedKey, error) {
+	if len(seed) < MinSeedBytes || len(seed) > MaxSeedBytes {
+		return nil, newError("NewMasterKey", 0, ErrorSeedSize)
+	}
+
+	mac := hmac.New(sha512.New, masterKeyHMACKey)
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	defer zero(sum)
+
+	result, err := EcSeckeyVerify(ctx, sum[:32])
+	if err != nil {
+		return nil, err
+	}
+	if result != 1 {
+		return nil, newError("NewMasterKey", result, ErrorDeriveChildKey)
+	}
+	return newExtendedPrivateKey(ctx, version, 0, [4]byte{}, 0, sum[32:], sum[:32])
+}
+
+func newExtendedPrivateKey(
+	ctx *Context,
+	version ExtendedKeyVersion,
+	depth uint8,
+	parentFingerprint [4]byte,
+	childNumber uint32,
+	chainCode []byte,
+	seckey []byte,
+) (*ExtendedKey, error) {
+	privateKey, err := NewSecretScalar(seckey)
+	if err != nil {
+		return nil, err
+	}
+	_, publicKey, err := EcPubkeyCreate(ctx, seckey)
+	if err != nil {
+		privateKey.Zero()
+		return nil, err
+	}
+
+	key := &ExtendedKey{
+		version:           version,
+		depth:             depth,
+		parentFingerprint: parentFingerprint,
+		childNumber:       childNumber,
+		privateKey:        privateKey,
+		publicKey:         publicKey,
+	}
+	copy(key.chainCode[:], chainCode)
+	return key, nil
+}
+
+// Derive returns the child key at index, which is hardened if index is at
+// least HardenedKeyStart. The child of a private key is private, the child
+// of a public key is public, and hardened children can only be derived
+// from private keys. In the rare case the child key is invalid, an error is
+// returned and the next index should be used, as BIP32 recommends.
+func (k *ExtendedKey) Derive(ctx *Context, index uint32) (*ExtendedKey, error) {
+	if k.depth == 0xff {
+		return nil, newError("ExtendedKey.Derive", 0, ErrorDerivationDepth)
+	}
+	hardened := index >= HardenedKeyStart
+	if hardened && !k.IsPrivate() {
+		return nil, newError("ExtendedKey.Derive", 0, ErrorHardenedFromPublic)
+	}
+
+	parentPubkey := k.publicKey.Bytes(true)
+	data := make([]byte, 0, 1+LenPrivateKey+4)
+	if hardened {
+		data = append(data, 0)
+		data = append(data, k.privateKey[:]...)
+	} else {
+		data = append(data, parentPubkey...)
+	}
+	var indexBytes [4]byte
+	binary.BigEndian.PutUint32(indexBytes[:], index)
+	data = append(data, indexBytes[:]...)
+	defer zero(data)
+
+	mac := hmac.New(sha512.New, k.chainCode[:])
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	defer zero(sum)
+	tweak, chainCode := sum[:32], sum[32:]
+
+	var fingerprint [4]byte
+	copy(fingerprint[:], hash160(parentPubkey))
+
+	if k.IsPrivate() {
+		seckey := make([]byte, LenPrivateKey)
+		defer zero(seckey)
+		copy(seckey, k.privateKey[:])
+		if _, err := EcPrivKeyTweakAdd(ctx, seckey, tweak); err != nil {
+			if errors.Is(err, ErrorTweakingPrivateKey) {
+				return nil, newError("ExtendedKey.Derive", 0, ErrorDeriveChildKey)
+			}
+			return nil, err
+		}
+		return newExtendedPrivateKey(ctx, k.version, k.depth+1, fingerprint, index, chainCode, seckey)
+	}
+
+	publicKey := newPublicKey()
+	*publicKey.pk = *k.publicKey.pk
+	if _, err := EcPubKeyTweakAdd(ctx, publicKey, tweak); err != nil {
+		if errors.Is(err, ErrorTweakingPublicKey) {
+			return nil, newError("ExtendedKey.Derive", 0, ErrorDeriveChildKey)
+		}
+		return nil, err
+	}
+	child := &ExtendedKey{
+		version:           k.version,
+		depth:             k.depth + 1,
+		parentFingerprint: fingerprint,
+		childNumber:       index,
+		publicKey:         publicKey,
+	}
+	copy(child.chainCode[:], chainCode)
+	return child, nil
+}
+
+// DerivePath derives the descendant of the key at the given path, see
+// ParseDerivationPath.
+func (k *ExtendedKey) DerivePath(ctx *Context, path string) (*ExtendedKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key := k
+	for _, index := range indexes {
+		child, err := key.Derive(ctx, index)
+		if key != k {
+			key.Zero()
+		}
+		if err != nil {
+			return nil, err
+		}
+		key = child
+	}
+	return key, nil
+}
+
+// ParseDerivationPath parses a derivation path such as "m/84'/1776'/0'"
+// into the list of indexes of the keys along the path. The leading "m/" is
+// optional, and hardened indexes are marked by a trailing ', h or H.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	path = strings.TrimSpace(path)
+	if path == "m" || path == "" {
+		return []uint32{}, nil
+	}
+	path = strings.TrimPrefix(path, "m/")
+
+	elements := strings.Split(path, "/")
+	indexes := make([]uint32, 0, len(elements))
+	for i, element := range elements {
+		offset := uint32(0)
+		if n := len(element); n > 0 && strings.ContainsAny(element[n-1:], "'hH") {
+			offset = HardenedKeyStart
+			element = element[:n-1]
+		}
+		// ParseUint would accept a leading sign or underscores
+		if element == "" || strings.Trim(element, "0123456789") != "" {
+			return nil, newIndexError("ParseDerivationPath", i, ErrorDerivationPath)
+		}
+		index, err := strconv.ParseUint(element, 10, 32)
+		if err != nil || uint32(index) >= HardenedKeyStart {
+			return nil, newIndexError("ParseDerivationPath", i, ErrorDerivationPath)
+		}
+		indexes = append(indexes, uint32(index)+offset)
+	}
+	return indexes, nil
+}
+
+// Neuter returns the public extended key of k, which is returned as is if
+// it is already public.
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	if !k.IsPrivate() {
+		return k
+	}
+	return &ExtendedKey{
+		version:           k.version,
+		depth:             k.depth,
+		parentFingerprint: k.parentFingerprint,
+		childNumber:       k.childNumber,
+		chainCode:         k.chainCode,
+		publicKey:         k.publicKey,
+	}
+}
+
+// IsPrivate reports whether the key holds a private key
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.privateKey != nil
+}
+
+// Depth returns the depth of the key in the tree, 0 for the master key
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// ChildNumber returns the index of the key in the children of its parent
+func (k *ExtendedKey) ChildNumber() uint32 {
+	return k.childNumber
+}
+
+// ParentFingerprint returns the fingerprint of the parent of the key, all
+// zeros for the master key
+func (k *ExtendedKey) ParentFingerprint() []byte {
+	return append([]byte{}, k.parentFingerprint[:]...)
+}
+
+// Fingerprint returns the first 4 bytes of the Hash160 of the compressed
+// public key, which identify the key as parent of its children
+func (k *ExtendedKey) Fingerprint() []byte {
+	return hash160(k.publicKey.Bytes(true))[:LenFingerprint]
+}
+
+// ChainCode returns the chain code of the key
+func (k *ExtendedKey) ChainCode() []byte {
+	return append([]byte{}, k.chainCode[:]...)
+}
+
+// PublicKey returns the public key
+func (k *ExtendedKey) PublicKey() *PublicKey {
+	pk := newPublicKey()
+	*pk.pk = *k.publicKey.pk
+	return pk
+}
+
+// PrivateKey returns a copy of the private key, to be wiped by the caller,
+// or an error if the key is public
+func (k *ExtendedKey) PrivateKey() (*PrivateKey, error) {
+	if !k.IsPrivate() {
+		return nil, newError("ExtendedKey.PrivateKey", 0, ErrorExtendedKeyNotPrivate)
+	}
+	privateKey := *k.privateKey
+	return &privateKey, nil
+}
+
+// Zero wipes the private key, after which the key is no longer usable
+func (k *ExtendedKey) Zero() {
+	if k.privateKey != nil {
+		k.privateKey.Zero()
+	}
+}
+
+// Serialize returns the 78 bytes serialization of the key defined by
+// BIP32.
+func (k *ExtendedKey) Serialize() []byte {
+	out := make([]byte, 0, LenExtendedKey)
+	if k.IsPrivate() {
+		out = append(out, k.version.Private[:]...)
+	} else {
+		out = append(out, k.version.Public[:]...)
+	}
+	out = append(out, k.depth)
+	out = append(out, k.parentFingerprint[:]...)
+	var childNumber [4]byte
+	binary.BigEndian.PutUint32(childNumber[:], k.childNumber)
+	out = append(out, childNumber[:]...)
+	out = append(out, k.chainCode[:]...)
+	if k.IsPrivate() {
+		out = append(out, 0)
+		return append(out, k.privateKey[:]...)
+	}
+	return append(out, k.publicKey.Bytes(true)...)
+}
+
+// Encode returns the base58check encoding of the serialization of the key,
+// an xprv or xpub string on mainnet. The encoding of a private key must be
+// handled as a secret.
+func (k *ExtendedKey) Encode() string {
+	serialized := k.Serialize()
+	defer zero(serialized)
+	return base58CheckEncode(serialized)
+}
+
+// ExtendedKeyParse parses the serialization of an extended key returned by
+// Serialize. The version must be one of MainnetExtendedKeyVersion and
+// TestnetExtendedKeyVersion. Parsing a private key requires a context
+// initialized for signing.
+func ExtendedKeyParse(ctx *Context, serialized []byte) (*ExtendedKey, error) {
+	if len(serialized) != LenExtendedKey {
+		return nil, newError("ExtendedKeyParse", 0, ErrorExtendedKeyEncoding)
+	}
+
+	var versionBytes [4]byte
+	copy(versionBytes[:], serialized[:4])
+	var version ExtendedKeyVersion
+	private, found := false, false
+	for _, v := range extendedKeyVersions {
+		if v.Private == versionBytes || v.Public == versionBytes {
+			version, private, found = v, v.Private == versionBytes, true
+			break
+		}
+	}
+	if !found {
+		return nil, newError("ExtendedKeyParse", 0, ErrorExtendedKeyVersion)
+	}
+
+	depth := serialized[4]
+	var parentFingerprint [4]byte
+	copy(parentFingerprint[:], serialized[5:9])
+	childNumber := binary.BigEndian.Uint32(serialized[9:13])
+	chainCode := serialized[13:45]
+	keyData := serialized[45:]
+	if depth == 0 && (parentFingerprint != [4]byte{} || childNumber != 0) {
+		return nil, newError("ExtendedKeyParse", 0, ErrorExtendedKeyEncoding)
+	}
+
+	if private {
+		if keyData[0] != 0 {
+			return nil, newError("ExtendedKeyParse", 0, ErrorExtendedKeyEncoding)
+		}
+		result, err := EcSeckeyVerify(ctx, keyData[1:])
+		if err != nil {
+			return nil, err
+		}
+		if result != 1 {
+			return nil, newError("ExtendedKeyParse", result, ErrorExtendedKeyEncoding)
+		}
+		return newExtendedPrivateKey(ctx, version, depth, parentFingerprint, childNumber, chainCode, keyData[1:])
+	}
+
+	if keyData[0] != 0x02 && keyData[0] != 0x03 {
+		return nil, newError("ExtendedKeyParse", 0, ErrorExtendedKeyEncoding)
+	}
+	_, publicKey, err := EcPubkeyParse(ctx, keyData)
+	if err != nil {
+		return nil, err
+	}
+	key := &ExtendedKey{
+		version:           version,
+		depth:             depth,
+		parentFingerprint: parentFingerprint,
+		childNumber:       childNumber,
+		publicKey:         publicKey,
+	}
+	copy(key.chainCode[:], chainCode)
+	return key, nil
+}
+
+// ExtendedKeyDecode parses the base58check encoding of an extended key
+// returned by Encode, see ExtendedKeyParse.
+func ExtendedKeyDecode(ctx *Context, encoded string) (*ExtendedKey, error) {
+	serialized, err := base58CheckDecode(encoded)
+	if err != nil {
+		return nil, newError("ExtendedKeyDecode", 0, err)
+	}
+	defer zero(serialized)
+	return ExtendedKeyParse(ctx, serialized)
+}
+
+// Equal reports whether two extended keys have the same serialization
+func (k *ExtendedKey) Equal(other *ExtendedKey) bool {
+	a, b := k.Serialize(), other.Serialize()
+	defer zero(a)
+	defer zero(b)
+	return hmac.Equal(a, b)
+}
@@ -0,0 +1,196 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type bip32TestVector struct {
+	Seed   string `json:"seed"`
+	Chains []struct {
+		Path string `json:"path"`
+		Xpub string `json:"xpub"`
+		Xprv string `json:"xprv"`
+	} `json:"chains"`
+}
+
+func readBip32TestVectors(t *testing.T) []bip32TestVector {
+	file, err := ioutil.ReadFile("testdata/bip32.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests struct {
+		Bip32 []bip32TestVector `json:"bip32"`
+	}
+	if err := json.Unmarshal(file, &tests); err != nil {
+		t.Fatal(err)
+	}
+	return tests.Bip32
+}
+
+func TestExtendedKeyDerive(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	for _, v := range readBip32TestVectors(t) {
+		seed, _ := hex.DecodeString(v.Seed)
+		master, err := NewMasterKey(ctx, seed, MainnetExtendedKeyVersion)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var parent *ExtendedKey
+		for _, chain := range v.Chains {
+			key, err := master.DerivePath(ctx, chain.Path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, chain.Xprv, key.Encode(), chain.Path)
+			assert.Equal(t, chain.Xpub, key.Neuter().Encode(), chain.Path)
+
+			// non-hardened children can be derived from the parent xpub
+			if parent != nil && key.ChildNumber() < HardenedKeyStart {
+				child, err := parent.Neuter().Derive(ctx, key.ChildNumber())
+				if err != nil {
+					t.Fatal(err)
+				}
+				assert.Equal(t, chain.Xpub, child.Encode(), chain.Path)
+				assert.False(t, child.IsPrivate())
+			}
+			if parent != nil {
+				assert.Equal(t, parent.Fingerprint(), key.ParentFingerprint())
+				assert.Equal(t, parent.Depth()+1, key.Depth())
+			}
+			parent = key
+		}
+	}
+}
+
+func TestExtendedKeyDecode(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	for _, v := range readBip32TestVectors(t) {
+		for _, chain := range v.Chains {
+			xprv, err := ExtendedKeyDecode(ctx, chain.Xprv)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.True(t, xprv.IsPrivate())
+			assert.Equal(t, chain.Xprv, xprv.Encode())
+
+			xpub, err := ExtendedKeyDecode(ctx, chain.Xpub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.False(t, xpub.IsPrivate())
+			assert.Equal(t, chain.Xpub, xpub.Encode())
+			assert.True(t, xpub.Equal(xprv.Neuter()))
+			assert.True(t, xpub.PublicKey().Equal(xprv.PublicKey()))
+
+			_, err = xpub.PrivateKey()
+			assert.True(t, errors.Is(err, ErrorExtendedKeyNotPrivate))
+		}
+	}
+}
+
+func TestExtendedKeyTestnet(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, _ := NewMasterKey(ctx, seed, TestnetExtendedKeyVersion)
+	assert.Equal(t, "tprv", master.Encode()[:4])
+	assert.Equal(t, "tpub", master.Neuter().Encode()[:4])
+
+	decoded, err := ExtendedKeyDecode(ctx, master.Encode())
+	assert.NoError(t, err)
+	assert.True(t, master.Equal(decoded))
+
+	child, _ := master.Derive(ctx, 0)
+	decoded, _ = ExtendedKeyDecode(ctx, child.Neuter().Encode())
+	assert.Equal(t, "tpub", decoded.Encode()[:4])
+}
+
+func TestExtendedKeyInvalid(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	_, err := NewMasterKey(ctx, seed[:15], MainnetExtendedKeyVersion)
+	assert.True(t, errors.Is(err, ErrorSeedSize))
+
+	master, _ := NewMasterKey(ctx, seed, MainnetExtendedKeyVersion)
+	_, err = master.Neuter().Derive(ctx, HardenedKeyStart)
+	assert.True(t, errors.Is(err, ErrorHardenedFromPublic))
+
+	serialized := master.Serialize()
+	tests := []struct {
+		name   string
+		mutate func(b []byte)
+		err    error
+	}{
+		{"unknown version", func(b []byte) { b[3] = 0 }, ErrorExtendedKeyVersion},
+		{"master with parent", func(b []byte) { b[5] = 1 }, ErrorExtendedKeyEncoding},
+		{"master with index", func(b []byte) { b[12] = 1 }, ErrorExtendedKeyEncoding},
+		{"private key prefix", func(b []byte) { b[45] = 2 }, ErrorExtendedKeyEncoding},
+		{"private key zero", func(b []byte) { copy(b[46:], make([]byte, 32)) }, ErrorExtendedKeyEncoding},
+		{"public version for private key", func(b []byte) { copy(b, MainnetExtendedKeyVersion.Public[:]) }, ErrorExtendedKeyEncoding},
+	}
+	for _, tt := range tests {
+		b := append([]byte{}, serialized...)
+		tt.mutate(b)
+		_, err := ExtendedKeyParse(ctx, b)
+		assert.True(t, errors.Is(err, tt.err), tt.name)
+	}
+
+	_, err = ExtendedKeyParse(ctx, serialized[1:])
+	assert.True(t, errors.Is(err, ErrorExtendedKeyEncoding))
+
+	encoded := []byte(master.Encode())
+	encoded[len(encoded)-1] ^= 1
+	_, err = ExtendedKeyDecode(ctx, string(encoded))
+	assert.True(t, errors.Is(err, ErrorBase58Checksum))
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		indexes []uint32
+	}{
+		{"m", []uint32{}},
+		{"m/0", []uint32{0}},
+		{"m/84'/1776'/0'", []uint32{HardenedKeyStart + 84, HardenedKeyStart + 1776, HardenedKeyStart}},
+		{"84h/1776H/0/1", []uint32{HardenedKeyStart + 84, HardenedKeyStart + 1776, 0, 1}},
+		{"m/2147483647'", []uint32{0xffffffff}},
+	}
+	for _, tt := range tests {
+		indexes, err := ParseDerivationPath(tt.path)
+		assert.NoError(t, err, tt.path)
+		assert.Equal(t, tt.indexes, indexes, tt.path)
+	}
+
+	for _, path := range []string{"m/", "/0", "m//0", "m/2147483648", "m/-1", "m/+1", "m/0''", "m/x", "m/1_0", "M/0"} {
+		_, err := ParseDerivationPath(path)
+		assert.True(t, errors.Is(err, ErrorDerivationPath), path)
+	}
+}
+
+func TestExtendedKeyZero(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	master, _ := NewMasterKey(ctx, seed, MainnetExtendedKeyVersion)
+	privateKey, err := master.PrivateKey()
+	assert.NoError(t, err)
+
+	master.Zero()
+	assert.False(t, privateKey.IsZero())
+	assert.True(t, master.privateKey.IsZero())
+}
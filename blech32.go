@@ -0,0 +1,171 @@
+package secp256k1
+
+import (
+	"errors"
+	"strings"
+)
+
+// Blech32 is the variant of bech32 used by Elements for confidential segwit
+// addresses, which are too long for the error detection guarantees of
+// bech32: it uses a 12 characters checksum computed with a degree 12 BCH
+// code. Like bech32m, blech32m only differs from blech32 by the constant
+// the checksum is xored with.
+
+const (
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+	blech32ChecksumLen = 12
+	// blech32MaxLen is the maximum length of a blech32 string accepted by
+	// Elements
+	blech32MaxLen = 1000
+
+	blech32Const  uint64 = 1
+	blech32mConst uint64 = 0x455972a3350f7a1
+)
+
+var blech32Generator = [5]uint64{
+	0x7d52fba40bd886,
+	0x5e8dbf1a03950c,
+	0x1c3a3c74072a18,
+	0x385d72fa0e5139,
+	0x7093e5a608865b,
+}
+
+var (
+	ErrorBlech32Encoding = errors.New("invalid blech32 encoding")
+	ErrorBlech32Checksum = errors.New("invalid blech32 checksum")
+)
+
+// blech32Encoding selects the constant of the checksum
+type blech32Encoding uint64
+
+const (
+	blech32  = blech32Encoding(blech32Const)
+	blech32m = blech32Encoding(blech32mConst)
+)
+
+func blech32Polymod(values []byte) uint64 {
+	chk := uint64(1)
+	for _, v := range values {
+		top := chk >> 55
+		chk = (chk&0x7fffffffffffff)<<5 ^ uint64(v)
+		for i, g := range blech32Generator {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= g
+			}
+		}
+	}
+	return chk
+}
+
+func blech32HRPExpand(hrp string) []byte {
+	out := make([]byte, 0, 2*len(hrp)+1)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]>>5)
+	}
+	out = append(out, 0)
+	for i := 0; i < len(hrp); i++ {
+		out = append(out, hrp[i]&31)
+	}
+	return out
+}
+
+func blech32Checksum(hrp string, data []byte, encoding blech32Encoding) []byte {
+	values := append(blech32HRPExpand(hrp), data...)
+	values = append(values, make([]byte, blech32ChecksumLen)...)
+	mod := blech32Polymod(values) ^ uint64(encoding)
+	checksum := make([]byte, blech32ChecksumLen)
+	for i := range checksum {
+		checksum[i] = byte(mod>>uint(5*(blech32ChecksumLen-1-i))) & 31
+	}
+	return checksum
+}
+
+// blech32Encode encodes the 5 bits values of data with the human readable
+// part hrp, which must be lowercase.
+func blech32Encode(hrp string, data []byte, encoding blech32Encoding) string {
+	var sb strings.Builder
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range data {
+		sb.WriteByte(bech32Charset[v])
+	}
+	for _, v := range blech32Checksum(hrp, data, encoding) {
+		sb.WriteByte(bech32Charset[v])
+	}
+	return sb.String()
+}
+
+// blech32Decode decodes a blech32 or blech32m string into its lowercase
+// human readable part and its 5 bits values, without the checksum.
+func blech32Decode(s string) (string, []byte, blech32Encoding, error) {
+	if len(s) > blech32MaxLen {
+		return "", nil, 0, ErrorBlech32Encoding
+	}
+	lower, upper := false, false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 {
+			return "", nil, 0, ErrorBlech32Encoding
+		}
+		lower = lower || (c >= 'a' && c <= 'z')
+		upper = upper || (c >= 'A' && c <= 'Z')
+	}
+	if lower && upper {
+		return "", nil, 0, ErrorBlech32Encoding
+	}
+	s = strings.ToLower(s)
+
+	sep := strings.LastIndexByte(s, '1')
+	if sep < 1 || sep+blech32ChecksumLen+1 > len(s) {
+		return "", nil, 0, ErrorBlech32Encoding
+	}
+	hrp := s[:sep]
+	data := make([]byte, 0, len(s)-sep-1)
+	for i := sep + 1; i < len(s); i++ {
+		v := strings.IndexByte(bech32Charset, s[i])
+		if v < 0 {
+			return "", nil, 0, ErrorBlech32Encoding
+		}
+		data = append(data, byte(v))
+	}
+
+	var encoding blech32Encoding
+	switch blech32Polymod(append(blech32HRPExpand(hrp), data...)) {
+	case blech32Const:
+		encoding = blech32
+	case blech32mConst:
+		encoding = blech32m
+	default:
+		return "", nil, 0, ErrorBlech32Checksum
+	}
+	return hrp, data[:len(data)-blech32ChecksumLen], encoding, nil
+}
+
+// convertBits regroups the fromBits bits values of data into toBits bits
+// values, padding the last one with zeros if pad is true, otherwise
+// requiring the padding to be shorter than fromBits and all zeros.
+func convertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, bool) {
+	acc, bits := uint32(0), uint(0)
+	maxv := uint32(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, false
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, false
+	}
+	return out, true
+}
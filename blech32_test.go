@@ -0,0 +1,67 @@
+package secp256k1
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlech32(t *testing.T) {
+	data := []byte{0, 1, 2, 3, 31, 30, 29}
+	for _, encoding := range []blech32Encoding{blech32, blech32m} {
+		encoded := blech32Encode("el", data, encoding)
+
+		for _, s := range []string{encoded, strings.ToUpper(encoded)} {
+			hrp, decoded, decodedEncoding, err := blech32Decode(s)
+			assert.NoError(t, err)
+			assert.Equal(t, "el", hrp)
+			assert.Equal(t, data, decoded)
+			assert.Equal(t, encoding, decodedEncoding)
+		}
+	}
+}
+
+func TestBlech32Invalid(t *testing.T) {
+	valid := blech32Encode("el", []byte{0, 1, 2, 3}, blech32)
+
+	// every single character substitution is detected
+	for i := len("el1"); i < len(valid); i++ {
+		b := []byte(valid)
+		if b[i] == 'q' {
+			b[i] = 'p'
+		} else {
+			b[i] = 'q'
+		}
+		_, _, _, err := blech32Decode(string(b))
+		assert.Equal(t, ErrorBlech32Checksum, err, string(b))
+	}
+
+	mixed := "E" + valid[1:]
+	tooLong := "el1" + strings.Repeat("q", blech32MaxLen)
+	for _, s := range []string{mixed, tooLong, "el1qqqqqqqqqqq", "1" + valid[3:], valid[:3] + "b" + valid[4:], "el 1" + valid[3:]} {
+		_, _, _, err := blech32Decode(s)
+		assert.Equal(t, ErrorBlech32Encoding, err, s)
+	}
+}
+
+func TestConvertBits(t *testing.T) {
+	data := []byte{0xff, 0x00, 0xa5}
+	fiveBits, ok := convertBits(data, 8, 5, true)
+	assert.True(t, ok)
+	assert.Equal(t, []byte{31, 28, 0, 10, 10}, fiveBits)
+
+	eightBits, ok := convertBits(fiveBits, 5, 8, false)
+	assert.True(t, ok)
+	assert.Equal(t, data, eightBits)
+
+	// non-zero padding
+	_, ok = convertBits([]byte{31, 28, 0, 10, 11}, 5, 8, false)
+	assert.False(t, ok)
+	// too much padding
+	_, ok = convertBits([]byte{31, 28, 0, 10, 10, 0}, 5, 8, false)
+	assert.False(t, ok)
+	// out of range value
+	_, ok = convertBits([]byte{32}, 5, 8, false)
+	assert.False(t, ok)
+}
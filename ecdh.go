@@ -7,11 +7,24 @@ package secp256k1
 static secp256k1_pubkey** makePubkeyArray(int size) { return !size ? NULL : calloc(sizeof(secp256k1_pubkey*), size); }
 static void setArrayPubkey(secp256k1_pubkey **a, secp256k1_pubkey *pubkey, int n) { if (a) a[n] = pubkey; }
 static void freePubkeyArray(secp256k1_pubkey **a) { if (a) free(a); }
+#include "string.h"
+static int ecdhHashXY(unsigned char *output, const unsigned char *x, const unsigned char *y, void *data) {
+	(void)data;
+	memcpy(output, x, 32);
+	memcpy(output + 32, y, 32);
+	return 1;
+}
+static int ecdhXY(const secp256k1_context* ctx, unsigned char *output, const secp256k1_pubkey *pubkey, const unsigned char *privkey) {
+	return secp256k1_ecdh(ctx, output, pubkey, privkey, ecdhHashXY, NULL);
+}
 */
 import "C"
 
 import (
+	"crypto/sha256"
 	"errors"
+	"io"
+	"runtime"
 	"unsafe"
 )
 
@@ -24,16 +37,58 @@ const (
 	LenPrivateKey   int = 32
 	LenCompressed   int = 33
 	LenUncompressed int = 65
+)
 
-	ErrorPrivateKeySize     string = "private key must be exactly 32 bytes"
-	ErrorEcdh               string = "unable to do ecdh"
-	ErrorPublicKeyCreate    string = "unable to produce public key"
-	ErrorPublicKeySize      string = "public key must be 33 or 65 bytes"
-	ErrorPublicKeyParse     string = "unable to parse this public key"
-	ErrorTweakingPublicKey  string = "unable to tweak this public key"
-	ErrorTweakSize          string = "tweak must be exactly 32 bytes"
-	ErrorPublicKeyCombine   string = "unable to combine public key"
-	ErrorTweakingPrivateKey string = "unable to tweak this private key"
+var (
+	ErrorPrivateKeySize     = errors.New("private key must be exactly 32 bytes")
+	ErrorEcdh               = errors.New("unable to do ecdh")
+	ErrorPublicKeyCreate    = errors.New("unable to produce public key")
+	ErrorPublicKeySize      = errors.New("public key must be 33 or 65 bytes")
+	ErrorPublicKeyParse     = errors.New("unable to parse this public key")
+	ErrorTweakingPublicKey  = errors.New("unable to tweak this public key")
+	ErrorTweakSize          = errors.New("tweak must be exactly 32 bytes")
+	ErrorPublicKeyCombine   = errors.New("unable to combine public key")
+	ErrorTweakingPrivateKey = errors.New("unable to tweak this private key")
+	ErrorPublicKeyCount     = errors.New("must provide at least one public key")
+)
+
+// EcdhHashFunction derives the ECDH secret from the 32 bytes X and Y
+// coordinates of the shared point. It must not retain x and y, which are
+// wiped as soon as it returns.
+type EcdhHashFunction func(x, y []byte) ([]byte, error)
+
+var (
+	// EcdhHashSHA256 returns the SHA256 of the compressed shared point, the
+	// default of the library and the secret returned by Ecdh
+	EcdhHashSHA256 EcdhHashFunction = func(x, y []byte) ([]byte, error) {
+		h := sha256.New()
+		h.Write([]byte{0x02 | y[31]&1})
+		h.Write(x)
+		return h.Sum(nil), nil
+	}
+	// EcdhHashSHA256X returns the SHA256 of the X coordinate of the shared
+	// point
+	EcdhHashSHA256X EcdhHashFunction = func(x, y []byte) ([]byte, error) {
+		hash := sha256.Sum256(x)
+		return hash[:], nil
+	}
+	// EcdhHashX returns the X coordinate of the shared point, unhashed
+	EcdhHashX EcdhHashFunction = func(x, y []byte) ([]byte, error) {
+		return append([]byte{}, x...), nil
+	}
+	// EcdhHashCompressed returns the 33 bytes compressed serialization of
+	// the shared point, unhashed
+	EcdhHashCompressed EcdhHashFunction = func(x, y []byte) ([]byte, error) {
+		return append([]byte{0x02 | y[31]&1}, x...), nil
+	}
+	// EcdhHashUncompressed returns the 65 bytes uncompressed serialization
+	// of the shared point, unhashed
+	EcdhHashUncompressed EcdhHashFunction = func(x, y []byte) ([]byte, error) {
+		point := make([]byte, 0, LenUncompressed)
+		point = append(point, 0x04)
+		point = append(point, x...)
+		return append(point, y...), nil
+	}
 )
 
 // PublicKey wraps a *secp256k1_pubkey, which contains the prefix plus
@@ -42,23 +97,102 @@ type PublicKey struct {
 	pk *C.secp256k1_pubkey
 }
 
+// EcSeckeyVerify checks that a secret key is valid, that is a non-zero
+// scalar lower than the order of the group. The return code is 1 if the
+// secret key is valid, 0 otherwise, in which case no error is returned.
+func EcSeckeyVerify(ctx *Context, seckey []byte) (int, error) {
+	if len(seckey) != LenPrivateKey {
+		return 0, newError("EcSeckeyVerify", 0, ErrorPrivateKeySize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, newError("EcSeckeyVerify", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_ec_seckey_verify", func() C.int {
+		return C.secp256k1_ec_seckey_verify(ctx.ctx, cBuf(seckey))
+	})
+	if err != nil {
+		return 0, newError("EcSeckeyVerify", 0, err)
+	}
+	return result, nil
+}
+
+// GeneratePrivateKey is GenerateSecretScalar returning a copy of the secret
+// key in a slice, which the caller is responsible for wiping.
+//
+// Deprecated: use GenerateSecretScalar, which does not copy the key.
+func GeneratePrivateKey(ctx *Context, rand io.Reader) ([]byte, error) {
+	s, err := GenerateSecretScalar(ctx, rand)
+	if err != nil {
+		return nil, err
+	}
+	seckey := make([]byte, LenPrivateKey)
+	copy(seckey, s[:])
+	s.Zero()
+	return seckey, nil
+}
+
+// GenerateSecretScalar returns a random valid secret scalar read from rand,
+// usually crypto/rand.Reader, to be used as a secret key, a blinding factor
+// or a nonce, and wiped with Zero once no longer needed. Candidates that are
+// not valid secret keys are discarded, which happens with a probability of
+// around 1 in 2^128. An error is returned if rand fails.
+func GenerateSecretScalar(ctx *Context, rand io.Reader) (*SecretScalar, error) {
+	seckey := new(SecretScalar)
+	for {
+		if _, err := io.ReadFull(rand, seckey[:]); err != nil {
+			seckey.Zero()
+			return nil, newError("GenerateSecretScalar", 0, err)
+		}
+		result, err := EcSeckeyVerify(ctx, seckey[:])
+		if err != nil {
+			seckey.Zero()
+			return nil, err
+		}
+		if result == 1 {
+			return seckey, nil
+		}
+	}
+}
+
 // EcPubkeyCreate will compute the public key for a secret key. The
 // return code is 1 and the key returned if the secret was valid.
 // Otherwise, the return code is 0, and an error is returned. The key
 // length must be 32-bytes.
 func EcPubkeyCreate(ctx *Context, seckey []byte) (int, *PublicKey, error) {
 	if len(seckey) != LenPrivateKey {
-		return 0, nil, errors.New(ErrorPrivateKeySize)
+		return 0, nil, newError("EcPubkeyCreate", 0, ErrorPrivateKeySize)
 	}
 
+	if err := ctx.require(ContextSign); err != nil {
+		return 0, nil, newError("EcPubkeyCreate", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
 	pk := newPublicKey()
-	result := int(C.secp256k1_ec_pubkey_create(ctx.ctx, pk.pk, cBuf(seckey[:])))
+	result, err := call("secp256k1_ec_pubkey_create", func() C.int {
+		return C.secp256k1_ec_pubkey_create(ctx.ctx, pk.pk, cBuf(seckey[:]))
+	})
+	if err != nil {
+		return 0, nil, newError("EcPubkeyCreate", 0, err)
+	}
 	if result != 1 {
-		return result, nil, errors.New(ErrorPublicKeyCreate)
+		return result, nil, newError("EcPubkeyCreate", result, ErrorPublicKeyCreate)
 	}
 	return result, pk, nil
 }
 
+// EcPubkeyCreateSecret is EcPubkeyCreate with a secret key held in a
+// SecretScalar
+func EcPubkeyCreateSecret(ctx *Context, seckey *SecretScalar) (int, *PublicKey, error) {
+	if seckey == nil {
+		return 0, nil, newError("EcPubkeyCreateSecret", 0, ErrorSecretScalarNil)
+	}
+	return EcPubkeyCreate(ctx, seckey[:])
+}
+
 func newPublicKey() *PublicKey {
 	return &PublicKey{
 		pk: &C.secp256k1_pubkey{},
@@ -69,19 +203,29 @@ func newPublicKey() *PublicKey {
 // object. The function will reject any input of zero bytes in length.
 // This function supports parsing compressed (33 bytes, header byte 0x02 or
 // 0x03), uncompressed (65 bytes, header byte 0x04), or hybrid (65 bytes,
-// header byte 0x06 or 0x07) format public keys. The return code is 1 if
-// the public key was fully valid, or 0 if the public key was invalid or
-// could not be parsed.
+// header byte 0x06 or 0x07) format public keys, use EcPubkeyParseStrict to
+// reject the latter. The return code is 1 if the public key was fully
+// valid, or 0 if the public key was invalid or could not be parsed.
 func EcPubkeyParse(ctx *Context, publicKey []byte) (int, *PublicKey, error) {
 	l := len(publicKey)
 	if l < 1 {
-		return 0, nil, errors.New(ErrorPublicKeySize)
+		return 0, nil, newError("EcPubkeyParse", 0, ErrorPublicKeySize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcPubkeyParse", 0, err)
 	}
+	defer runtime.KeepAlive(ctx)
 
 	pk := newPublicKey()
-	result := int(C.secp256k1_ec_pubkey_parse(ctx.ctx, pk.pk, cBuf(publicKey), C.size_t(l)))
+	result, err := call("secp256k1_ec_pubkey_parse", func() C.int {
+		return C.secp256k1_ec_pubkey_parse(ctx.ctx, pk.pk, cBuf(publicKey), C.size_t(l))
+	})
+	if err != nil {
+		return 0, nil, newError("EcPubkeyParse", 0, err)
+	}
 	if result != 1 {
-		return result, nil, errors.New(ErrorPublicKeyParse)
+		return result, nil, newError("EcPubkeyParse", result, ErrorPublicKeyParse)
 	}
 	return result, pk, nil
 }
@@ -92,6 +236,14 @@ func EcPubkeyParse(ctx *Context, publicKey []byte) (int, *PublicKey, error) {
 // function will always return 1, because the only
 // public key objects are valid ones.
 func EcPubkeySerialize(ctx *Context, publicKey *PublicKey, flags uint) (int, []byte, error) {
+	if publicKey == nil {
+		return 0, nil, newError("EcPubkeySerialize", 0, ErrorPublicKeyNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcPubkeySerialize", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
 	var size int
 	if flags == EcCompressed {
 		size = LenCompressed
@@ -101,36 +253,173 @@ func EcPubkeySerialize(ctx *Context, publicKey *PublicKey, flags uint) (int, []b
 
 	output := make([]C.uchar, size)
 	outputLen := C.size_t(size)
-	result := int(C.secp256k1_ec_pubkey_serialize(ctx.ctx, &output[0], &outputLen, publicKey.pk, C.uint(flags)))
+	result, err := call("secp256k1_ec_pubkey_serialize", func() C.int {
+		return C.secp256k1_ec_pubkey_serialize(ctx.ctx, &output[0], &outputLen, publicKey.pk, C.uint(flags))
+	})
+	if err != nil {
+		return 0, nil, newError("EcPubkeySerialize", 0, err)
+	}
 	return result, goBytes(output, C.int(outputLen)), nil
 }
 
 // Compute an EC Diffie-Hellman secret in constant time. Return code is
 // 1 if exponentiation was successful, or 0 if the scalar was invalid.
 func Ecdh(ctx *Context, pubKey *PublicKey, privKey []byte) (int, []byte, error) {
+	secret := make([]byte, LenPrivateKey)
+	result, err := ecdh("Ecdh", ctx, pubKey, privKey, secret)
+	if err != nil {
+		return result, []byte{}, err
+	}
+	return result, secret, nil
+}
+
+// EcdhSecret is Ecdh with the secret key and the shared secret held in
+// SecretScalars
+func EcdhSecret(ctx *Context, pubKey *PublicKey, privKey *SecretScalar) (int, *SecretScalar, error) {
+	if privKey == nil {
+		return 0, nil, newError("EcdhSecret", 0, ErrorSecretScalarNil)
+	}
+	secret := new(SecretScalar)
+	result, err := ecdh("EcdhSecret", ctx, pubKey, privKey[:], secret[:])
+	if err != nil {
+		return result, nil, err
+	}
+	return result, secret, nil
+}
+
+// ecdh writes the shared secret in secret, which is wiped on failure
+func ecdh(op string, ctx *Context, pubKey *PublicKey, privKey []byte, secret []byte) (int, error) {
 	if len(privKey) != LenPrivateKey {
-		return 0, []byte{}, errors.New(ErrorPrivateKeySize)
+		return 0, newError(op, 0, ErrorPrivateKeySize)
+	}
+	if pubKey == nil {
+		return 0, newError(op, 0, ErrorPublicKeyNil)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, newError(op, 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_ecdh", func() C.int {
+		return C.secp256k1_ecdh(ctx.ctx, cBuf(secret), pubKey.pk, cBuf(privKey), nil, nil)
+	})
+	if err != nil {
+		zero(secret)
+		return 0, newError(op, 0, err)
 	}
-	secret := make([]byte, LenPrivateKey)
-	result := int(C.secp256k1_ecdh(ctx.ctx, cBuf(secret[:]), pubKey.pk, cBuf(privKey[:]), nil, nil))
 	if result != 1 {
-		return result, []byte{}, errors.New(ErrorEcdh)
+		zero(secret)
+		return result, newError(op, result, ErrorEcdh)
+	}
+	return result, nil
+}
+
+// EcdhWithHash computes an EC Diffie-Hellman secret in constant time, like
+// Ecdh, deriving it from the shared point with the given hash function
+// instead of the default one. EcdhHashSHA256 is used if hashfp is nil. The
+// unhashed variants expose the shared point: it must be hashed by the
+// caller before being used as a key. Return code is 1 if exponentiation was
+// successful, or 0 if the scalar was invalid. An error returned by the hash
+// function is returned wrapped.
+func EcdhWithHash(ctx *Context, pubKey *PublicKey, privKey []byte, hashfp EcdhHashFunction) (int, []byte, error) {
+	if len(privKey) != LenPrivateKey {
+		return 0, nil, newError("EcdhWithHash", 0, ErrorPrivateKeySize)
+	}
+	if pubKey == nil {
+		return 0, nil, newError("EcdhWithHash", 0, ErrorPublicKeyNil)
+	}
+	if hashfp == nil {
+		hashfp = EcdhHashSHA256
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdhWithHash", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	point := make([]byte, 64)
+	defer zero(point)
+	result, err := call("secp256k1_ecdh", func() C.int {
+		return C.ecdhXY(ctx.ctx, cBuf(point), pubKey.pk, cBuf(privKey))
+	})
+	if err != nil {
+		return 0, nil, newError("EcdhWithHash", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("EcdhWithHash", result, ErrorEcdh)
+	}
+
+	secret, err := hashfp(point[:32], point[32:])
+	if err != nil {
+		return 0, nil, newError("EcdhWithHash", 0, err)
 	}
 	return result, secret, nil
 }
 
+// EcdhWithHashSecret is EcdhWithHash with the secret key held in a
+// SecretScalar
+func EcdhWithHashSecret(ctx *Context, pubKey *PublicKey, privKey *SecretScalar, hashfp EcdhHashFunction) (int, []byte, error) {
+	if privKey == nil {
+		return 0, nil, newError("EcdhWithHashSecret", 0, ErrorSecretScalarNil)
+	}
+	return EcdhWithHash(ctx, pubKey, privKey[:], hashfp)
+}
+
 // Tweak a public key by adding tweak times the generator to it. The
 // return code is 0 if the tweak was out of range (chance of around 1 in
 // 2^128 for uniformly random 32-byte arrays) or if the resulting public
 // key would be invalid. The return code is 1 otherwise.
 func EcPubKeyTweakAdd(ctx *Context, pk *PublicKey, tweak []byte) (int, error) {
 	if len(tweak) != LenPrivateKey {
-		return 0, errors.New(ErrorTweakSize)
+		return 0, newError("EcPubKeyTweakAdd", 0, ErrorTweakSize)
 	}
+	if pk == nil {
+		return 0, newError("EcPubKeyTweakAdd", 0, ErrorPublicKeyNil)
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, newError("EcPubKeyTweakAdd", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
 
-	result := int(C.secp256k1_ec_pubkey_tweak_add(ctx.ctx, pk.pk, cBuf(tweak)))
+	result, err := call("secp256k1_ec_pubkey_tweak_add", func() C.int {
+		return C.secp256k1_ec_pubkey_tweak_add(ctx.ctx, pk.pk, cBuf(tweak))
+	})
+	if err != nil {
+		return 0, newError("EcPubKeyTweakAdd", 0, err)
+	}
 	if result != 1 {
-		return result, errors.New(ErrorTweakingPublicKey)
+		return result, newError("EcPubKeyTweakAdd", result, ErrorTweakingPublicKey)
+	}
+	return result, nil
+}
+
+// EcPubKeyTweakMul tweaks a public key in place by multiplying it by tweak.
+// The context must be initialized for verification. The return code is 0 if
+// the tweak was out of range (chance of around 1 in 2^128 for uniformly
+// random 32-byte arrays) or zero. The return code is 1 otherwise.
+func EcPubKeyTweakMul(ctx *Context, pk *PublicKey, tweak []byte) (int, error) {
+	if len(tweak) != LenPrivateKey {
+		return 0, newError("EcPubKeyTweakMul", 0, ErrorTweakSize)
+	}
+	if pk == nil {
+		return 0, newError("EcPubKeyTweakMul", 0, ErrorPublicKeyNil)
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, newError("EcPubKeyTweakMul", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_ec_pubkey_tweak_mul", func() C.int {
+		return C.secp256k1_ec_pubkey_tweak_mul(ctx.ctx, pk.pk, cBuf(tweak))
+	})
+	if err != nil {
+		return 0, newError("EcPubKeyTweakMul", 0, err)
+	}
+	if result != 1 {
+		return result, newError("EcPubKeyTweakMul", result, ErrorTweakingPublicKey)
 	}
 	return result, nil
 }
@@ -142,23 +431,55 @@ func EcPubKeyTweakAdd(ctx *Context, pk *PublicKey, tweak []byte) (int, error) {
 // complement of the private key). The return code is 1 otherwise.
 func EcPrivKeyTweakAdd(ctx *Context, seckey []byte, tweak []byte) (int, error) {
 	if len(tweak) != LenPrivateKey {
-		return 0, errors.New(ErrorTweakSize)
+		return 0, newError("EcPrivKeyTweakAdd", 0, ErrorTweakSize)
 	}
 	if len(seckey) != LenPrivateKey {
-		return 0, errors.New(ErrorPrivateKeySize)
+		return 0, newError("EcPrivKeyTweakAdd", 0, ErrorPrivateKeySize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, newError("EcPrivKeyTweakAdd", 0, err)
 	}
+	defer runtime.KeepAlive(ctx)
 
-	result := int(C.secp256k1_ec_privkey_tweak_add(ctx.ctx, (*C.uchar)(unsafe.Pointer(&seckey[0])), cBuf(tweak[:])))
+	result, err := call("secp256k1_ec_privkey_tweak_add", func() C.int {
+		return C.secp256k1_ec_privkey_tweak_add(ctx.ctx, (*C.uchar)(unsafe.Pointer(&seckey[0])), cBuf(tweak[:]))
+	})
+	if err != nil {
+		return 0, newError("EcPrivKeyTweakAdd", 0, err)
+	}
 	if result != 1 {
-		return result, errors.New(ErrorTweakingPrivateKey)
+		return result, newError("EcPrivKeyTweakAdd", result, ErrorTweakingPrivateKey)
 	}
 	return result, nil
 }
 
+// EcPrivKeyTweakAddSecret is EcPrivKeyTweakAdd with the secret key held in
+// a SecretScalar, which is modified in place
+func EcPrivKeyTweakAddSecret(ctx *Context, seckey *SecretScalar, tweak []byte) (int, error) {
+	if seckey == nil {
+		return 0, newError("EcPrivKeyTweakAddSecret", 0, ErrorSecretScalarNil)
+	}
+	return EcPrivKeyTweakAdd(ctx, seckey[:], tweak)
+}
+
 // EcPubKeyNegate will negate a public key object in place. The return code
 // is always 1.
 func EcPubKeyNegate(ctx *Context, pubkey *PublicKey) (int, error) {
-	result := int(C.secp256k1_ec_pubkey_negate(ctx.ctx, pubkey.pk))
+	if pubkey == nil {
+		return 0, newError("EcPubKeyNegate", 0, ErrorPublicKeyNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, newError("EcPubKeyNegate", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_ec_pubkey_negate", func() C.int {
+		return C.secp256k1_ec_pubkey_negate(ctx.ctx, pubkey.pk)
+	})
+	if err != nil {
+		return 0, newError("EcPubKeyNegate", 0, err)
+	}
 	return result, nil
 }
 
@@ -166,21 +487,50 @@ func EcPubKeyNegate(ctx *Context, pubkey *PublicKey) (int, error) {
 // 1 if the operation was successful, or 0 if the length was invalid.
 func EcPrivKeyNegate(ctx *Context, seckey []byte) (int, error) {
 	if len(seckey) != LenPrivateKey {
-		return 0, errors.New(ErrorPrivateKeySize)
+		return 0, newError("EcPrivKeyNegate", 0, ErrorPrivateKeySize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, newError("EcPrivKeyNegate", 0, err)
 	}
+	defer runtime.KeepAlive(ctx)
 
-	result := int(C.secp256k1_ec_privkey_negate(ctx.ctx, (*C.uchar)(unsafe.Pointer(&seckey[0]))))
+	result, err := call("secp256k1_ec_privkey_negate", func() C.int {
+		return C.secp256k1_ec_privkey_negate(ctx.ctx, (*C.uchar)(unsafe.Pointer(&seckey[0])))
+	})
+	if err != nil {
+		return 0, newError("EcPrivKeyNegate", 0, err)
+	}
 	return result, nil
 }
 
+// EcPrivKeyNegateSecret is EcPrivKeyNegate with the secret key held in a
+// SecretScalar, which is negated in place
+func EcPrivKeyNegateSecret(ctx *Context, seckey *SecretScalar) (int, error) {
+	if seckey == nil {
+		return 0, newError("EcPrivKeyNegateSecret", 0, ErrorSecretScalarNil)
+	}
+	return EcPrivKeyNegate(ctx, seckey[:])
+}
+
 // EcPubKeyCombine will compute sum of all the provided public keys,
 // returning a new point. The error code is 1 if the sum is valid, 0
 // otherwise. There must be at least one public key.
 func EcPubKeyCombine(ctx *Context, vPk []*PublicKey) (int, *PublicKey, error) {
 	l := len(vPk)
 	if l < 1 {
-		return 0, nil, errors.New("must provide at least one public key")
+		return 0, nil, newError("EcPubKeyCombine", 0, ErrorPublicKeyCount)
+	}
+	for i, pk := range vPk {
+		if pk == nil {
+			return 0, nil, newIndexError("EcPubKeyCombine", i, ErrorPublicKeyParse)
+		}
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcPubKeyCombine", 0, err)
 	}
+	defer runtime.KeepAlive(ctx)
 
 	array := C.makePubkeyArray(C.int(l))
 	for i := 0; i < l; i++ {
@@ -190,9 +540,14 @@ func EcPubKeyCombine(ctx *Context, vPk []*PublicKey) (int, *PublicKey, error) {
 	defer C.freePubkeyArray(array)
 
 	pkOut := newPublicKey()
-	result := int(C.secp256k1_ec_pubkey_combine(ctx.ctx, pkOut.pk, array, C.size_t(l)))
+	result, err := call("secp256k1_ec_pubkey_combine", func() C.int {
+		return C.secp256k1_ec_pubkey_combine(ctx.ctx, pkOut.pk, array, C.size_t(l))
+	})
+	if err != nil {
+		return 0, nil, newError("EcPubKeyCombine", 0, err)
+	}
 	if result != 1 {
-		return result, nil, errors.New(ErrorPublicKeyCombine)
+		return result, nil, newError("EcPubKeyCombine", result, ErrorPublicKeyCombine)
 	}
 	return result, pkOut, nil
 }
@@ -202,15 +557,34 @@ func EcPubKeyCombine(ctx *Context, vPk []*PublicKey) (int, *PublicKey, error) {
 // random 32-byte arrays) or zero. The code is 1 otherwise.
 func EcPrivKeyTweakMul(ctx *Context, seckey []byte, tweak []byte) (int, error) {
 	if len(tweak) != LenPrivateKey {
-		return 0, errors.New(ErrorTweakSize)
+		return 0, newError("EcPrivKeyTweakMul", 0, ErrorTweakSize)
 	}
 	if len(seckey) != LenPrivateKey {
-		return 0, errors.New(ErrorPrivateKeySize)
+		return 0, newError("EcPrivKeyTweakMul", 0, ErrorPrivateKeySize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, newError("EcPrivKeyTweakMul", 0, err)
 	}
+	defer runtime.KeepAlive(ctx)
 
-	result := int(C.secp256k1_ec_privkey_tweak_mul(ctx.ctx, (*C.uchar)(unsafe.Pointer(&seckey[0])), cBuf(tweak[:])))
+	result, err := call("secp256k1_ec_privkey_tweak_mul", func() C.int {
+		return C.secp256k1_ec_privkey_tweak_mul(ctx.ctx, (*C.uchar)(unsafe.Pointer(&seckey[0])), cBuf(tweak[:]))
+	})
+	if err != nil {
+		return 0, newError("EcPrivKeyTweakMul", 0, err)
+	}
 	if result != 1 {
-		return result, errors.New(ErrorTweakingPrivateKey)
+		return result, newError("EcPrivKeyTweakMul", result, ErrorTweakingPrivateKey)
 	}
 	return result, nil
+}
+
+// EcPrivKeyTweakMulSecret is EcPrivKeyTweakMul with the secret key held in
+// a SecretScalar, which is modified in place
+func EcPrivKeyTweakMulSecret(ctx *Context, seckey *SecretScalar, tweak []byte) (int, error) {
+	if seckey == nil {
+		return 0, newError("EcPrivKeyTweakMulSecret", 0, ErrorSecretScalarNil)
+	}
+	return EcPrivKeyTweakMul(ctx, seckey[:], tweak)
 }
\ No newline at end of file
@@ -1,8 +1,13 @@
 package secp256k1_test
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"io"
 	"io/ioutil"
 	"testing"
 
@@ -56,6 +61,82 @@ func TestEcdh(t *testing.T) {
 
 }
 
+func TestEcdhWithHash(t *testing.T) {
+	ctx, err := secp256k1.ContextCreate(secp256k1.ContextBoth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer secp256k1.ContextDestroy(ctx)
+
+	file, err := ioutil.ReadFile("testdata/ecdh.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests map[string]interface{}
+	if err := json.Unmarshal(file, &tests); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, testVector := range tests["ecdh"].([]interface{}) {
+		v := testVector.(map[string]interface{})
+		privKey, _ := hex.DecodeString(v["privKey"].(string))
+		pubKeyBytes, _ := hex.DecodeString(v["pubkey"].(string))
+		_, pubKey, err := secp256k1.EcPubkeyParse(ctx, pubKeyBytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the vectors use the generator as public key, so the shared point
+		// is the public key of privKey
+		_, point, _ := secp256k1.EcPubkeyCreate(ctx, privKey)
+		_, compressed, _ := secp256k1.EcPubkeySerialize(ctx, point, secp256k1.EcCompressed)
+		_, uncompressed, _ := secp256k1.EcPubkeySerialize(ctx, point, secp256k1.EcUncompressed)
+		x := uncompressed[1:33]
+		sha256X := sha256.Sum256(x)
+
+		for _, tt := range []struct {
+			hashfp   secp256k1.EcdhHashFunction
+			expected string
+		}{
+			{nil, v["expected"].(string)},
+			{secp256k1.EcdhHashSHA256, v["expected"].(string)},
+			{secp256k1.EcdhHashSHA256X, hex.EncodeToString(sha256X[:])},
+			{secp256k1.EcdhHashX, hex.EncodeToString(x)},
+			{secp256k1.EcdhHashCompressed, hex.EncodeToString(compressed)},
+			{secp256k1.EcdhHashUncompressed, hex.EncodeToString(uncompressed)},
+		} {
+			result, secret, err := secp256k1.EcdhWithHash(ctx, pubKey, privKey, tt.hashfp)
+			spOK(t, result, err)
+			assert.Equal(t, tt.expected, hex.EncodeToString(secret))
+		}
+	}
+}
+
+func TestEcdhWithHashError(t *testing.T) {
+	ctx, err := secp256k1.ContextCreate(secp256k1.ContextBoth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer secp256k1.ContextDestroy(ctx)
+
+	privKey, _ := hex.DecodeString("d90314455f64c385db12f629c2adbecc576baebdfe70905a412747a689872760")
+	_, pubKey, _ := secp256k1.EcPubkeyCreate(ctx, privKey)
+
+	errHash := errors.New("hash error")
+	var retained []byte
+	_, _, err = secp256k1.EcdhWithHash(ctx, pubKey, privKey, func(x, y []byte) ([]byte, error) {
+		retained = x
+		return nil, errHash
+	})
+	assert.True(t, errors.Is(err, errHash))
+	// the coordinates are wiped once the hash function returns
+	assert.Equal(t, make([]byte, 32), retained)
+
+	result, _, err := secp256k1.EcdhWithHash(ctx, pubKey, make([]byte, 32), nil)
+	assert.Equal(t, 0, result)
+	assert.True(t, errors.Is(err, secp256k1.ErrorEcdh))
+}
+
 func TestPubKeyTweakAddF(t *testing.T) {
 	ctx, err := secp256k1.ContextCreate(secp256k1.ContextSign | secp256k1.ContextVerify)
 	if err != nil {
@@ -216,3 +297,110 @@ func spOK(t *testing.T, result interface{}, err error) {
 		assert.True(t, result)
 	}
 }
+
+func TestSeckeyVerify(t *testing.T) {
+	ctx, err := secp256k1.ContextCreate(secp256k1.ContextNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer secp256k1.ContextDestroy(ctx)
+
+	tests := []struct {
+		seckey string
+		valid  int
+	}{
+		{"0000000000000000000000000000000000000000000000000000000000000001", 1},
+		{"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140", 1},
+		{"d90314455f64c385db12f629c2adbecc576baebdfe70905a412747a689872760", 1},
+		{"0000000000000000000000000000000000000000000000000000000000000000", 0},
+		{"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 0},
+		{"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", 0},
+	}
+
+	for _, tt := range tests {
+		seckey, _ := hex.DecodeString(tt.seckey)
+		result, err := secp256k1.EcSeckeyVerify(ctx, seckey)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.valid, result, tt.seckey)
+	}
+
+	_, err = secp256k1.EcSeckeyVerify(ctx, make([]byte, 31))
+	assert.True(t, errors.Is(err, secp256k1.ErrorPrivateKeySize))
+}
+
+func TestGeneratePrivateKey(t *testing.T) {
+	ctx, err := secp256k1.ContextCreate(secp256k1.ContextNone)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer secp256k1.ContextDestroy(ctx)
+
+	seckey, err := secp256k1.GeneratePrivateKey(ctx, rand.Reader)
+	assert.NoError(t, err)
+	result, _ := secp256k1.EcSeckeyVerify(ctx, seckey)
+	assert.Equal(t, 1, result)
+
+	// invalid candidates are skipped
+	order, _ := hex.DecodeString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141")
+	valid, _ := hex.DecodeString("d90314455f64c385db12f629c2adbecc576baebdfe70905a412747a689872760")
+	stream := append(append(make([]byte, 32), order...), valid...)
+	seckey, err = secp256k1.GeneratePrivateKey(ctx, bytes.NewReader(stream))
+	assert.NoError(t, err)
+	assert.Equal(t, valid, seckey)
+
+	_, err = secp256k1.GeneratePrivateKey(ctx, bytes.NewReader(order))
+	assert.True(t, errors.Is(err, io.EOF))
+}
+
+func TestPubKeyTweakMul(t *testing.T) {
+	ctx, err := secp256k1.ContextCreate(secp256k1.ContextBoth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer secp256k1.ContextDestroy(ctx)
+
+	file, err := ioutil.ReadFile("testdata/privkey_tweak_mult_vectors.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests map[string]interface{}
+	if err := json.Unmarshal(file, &tests); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, testVector := range tests["tweak_mult"].([]interface{}) {
+		v := testVector.(map[string]interface{})
+		privKey, _ := hex.DecodeString(v["privkey"].(string))
+		tweak, _ := hex.DecodeString(v["tweak"].(string))
+		tweaked, _ := hex.DecodeString(v["tweaked"].(string))
+
+		// multiplying the public key matches multiplying the secret key
+		_, pubKey, err := secp256k1.EcPubkeyCreate(ctx, privKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r, err := secp256k1.EcPubKeyTweakMul(ctx, pubKey, tweak)
+		spOK(t, r, err)
+
+		_, expected, _ := secp256k1.EcPubkeyCreate(ctx, tweaked)
+		_, pubKeyBytes, _ := secp256k1.EcPubkeySerialize(ctx, pubKey, secp256k1.EcCompressed)
+		_, expectedBytes, _ := secp256k1.EcPubkeySerialize(ctx, expected, secp256k1.EcCompressed)
+		assert.Equal(t, expectedBytes, pubKeyBytes)
+	}
+
+	_, pubKey, _ := secp256k1.EcPubkeyParse(ctx, generatorBytes(t))
+	r, err := secp256k1.EcPubKeyTweakMul(ctx, pubKey, make([]byte, 32))
+	assert.Equal(t, 0, r)
+	assert.True(t, errors.Is(err, secp256k1.ErrorTweakingPublicKey))
+
+	_, err = secp256k1.EcPubKeyTweakMul(ctx, pubKey, make([]byte, 31))
+	assert.True(t, errors.Is(err, secp256k1.ErrorTweakSize))
+}
+
+func generatorBytes(t *testing.T) []byte {
+	g, err := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
@@ -0,0 +1,336 @@
+package secp256k1
+
+/*
+#include "include/secp256k1.h"
+#include "contrib/lax_der_parsing.h"
+#cgo CFLAGS: -I${SRCDIR}/secp256k1-zkp -I${SRCDIR}/secp256k1-zkp/src
+*/
+import "C"
+
+import (
+	"errors"
+	"runtime"
+)
+
+const (
+	// Length of elements byte representations
+	LenMessageHash      int = 32
+	LenCompactSignature int = 64
+	LenMaxDERSignature  int = 72
+
+	// Flags for EcdsaSignatureParse
+	SignatureParseStrict    = uint(0)
+	SignatureParseLax       = uint(1 << 0)
+	SignatureParseLowS      = uint(1 << 1)
+	SignatureParseNormalize = uint(1 << 2)
+)
+
+var (
+	ErrorMessageHashSize      = errors.New("message hash must be exactly 32 bytes")
+	ErrorCompactSignatureSize = errors.New("compact signature must be exactly 64 bytes")
+	ErrorSignatureParse       = errors.New("unable to parse this signature")
+	ErrorSignatureSerialize   = errors.New("unable to serialize this signature")
+	ErrorSignatureCreate      = errors.New("unable to produce signature")
+	ErrorSignatureHighS       = errors.New("signature is not in lower-S form")
+	ErrorSignatureNil         = errors.New("signature is nil")
+)
+
+// Signature wraps a *secp256k1_ecdsa_signature, an opaque 64-byte
+// representation of an ECDSA signature. Use the parsing and serialization
+// functions to convert it from and to the compact or DER formats.
+type Signature struct {
+	sig *C.secp256k1_ecdsa_signature
+}
+
+func newSignature() *Signature {
+	return &Signature{
+		sig: &C.secp256k1_ecdsa_signature{},
+	}
+}
+
+// EcdsaSignatureParseCompact parses an ECDSA signature in compact (64 bytes)
+// format, that is the 32-byte big endian R value followed by the 32-byte big
+// endian S value. The return code is 1 when the signature could be parsed,
+// 0 otherwise. An overflowing R or S value fails the parsing, while no
+// check is done on the S value being in the lower half of the order.
+func EcdsaSignatureParseCompact(ctx *Context, input64 []byte) (int, *Signature, error) {
+	if len(input64) != LenCompactSignature {
+		return 0, nil, newError("EcdsaSignatureParseCompact", 0, ErrorCompactSignatureSize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdsaSignatureParseCompact", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newSignature()
+	result, err := call("secp256k1_ecdsa_signature_parse_compact", func() C.int {
+		return C.secp256k1_ecdsa_signature_parse_compact(ctx.ctx, sig.sig, cBuf(input64))
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaSignatureParseCompact", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("EcdsaSignatureParseCompact", result, ErrorSignatureParse)
+	}
+	return result, sig, nil
+}
+
+// EcdsaSignatureParseDER parses a DER encoded ECDSA signature. The return
+// code is 1 when the signature could be parsed, 0 otherwise. The parser
+// strictly follows BIP66, only signatures that are valid DER are accepted.
+func EcdsaSignatureParseDER(ctx *Context, der []byte) (int, *Signature, error) {
+	if len(der) < 1 {
+		return 0, nil, newError("EcdsaSignatureParseDER", 0, ErrorSignatureParse)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdsaSignatureParseDER", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newSignature()
+	result, err := call("secp256k1_ecdsa_signature_parse_der", func() C.int {
+		return C.secp256k1_ecdsa_signature_parse_der(ctx.ctx, sig.sig, cBuf(der), C.size_t(len(der)))
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaSignatureParseDER", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("EcdsaSignatureParseDER", result, ErrorSignatureParse)
+	}
+	return result, sig, nil
+}
+
+// EcdsaSignatureParseDERLax parses a signature in "lax DER" format, as
+// implemented by contrib/lax_der_parsing.c of libsecp256k1. The return code
+// is 1 when the signature could be parsed, 0 otherwise.
+// Any valid DER signature is accepted, even if the encoded numbers are out
+// of range, as well as signatures which violate the DER spec in various
+// ways, like the ones included in the Bitcoin blockchain before BIP66 was
+// enforced. A parsed signature with out of range numbers fails the
+// verification for every message and public key.
+func EcdsaSignatureParseDERLax(ctx *Context, der []byte) (int, *Signature, error) {
+	if len(der) < 1 {
+		return 0, nil, newError("EcdsaSignatureParseDERLax", 0, ErrorSignatureParse)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdsaSignatureParseDERLax", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newSignature()
+	result, err := call("ecdsa_signature_parse_der_lax", func() C.int {
+		return C.ecdsa_signature_parse_der_lax(ctx.ctx, sig.sig, cBuf(der), C.size_t(len(der)))
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaSignatureParseDERLax", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("EcdsaSignatureParseDERLax", result, ErrorSignatureParse)
+	}
+	return result, sig, nil
+}
+
+// EcdsaSignatureParse parses a DER encoded signature with the strictness
+// given by a bitmask of flags:
+//
+//	SignatureParseStrict:    only valid DER is accepted (BIP66), the default.
+//	SignatureParseLax:       lax DER is accepted, see EcdsaSignatureParseDERLax.
+//	SignatureParseLowS:      signatures not in lower-S form are rejected with
+//	                         ErrorSignatureHighS (BIP62 rule 5).
+//	SignatureParseNormalize: signatures not in lower-S form are normalized,
+//	                         as Bitcoin and Elements do before verification.
+//
+// SignatureParseLowS is checked first, therefore it takes precedence over
+// SignatureParseNormalize when both are given.
+func EcdsaSignatureParse(ctx *Context, der []byte, flags uint) (int, *Signature, error) {
+	var (
+		result int
+		sig    *Signature
+		err    error
+	)
+	if flags&SignatureParseLax != 0 {
+		result, sig, err = EcdsaSignatureParseDERLax(ctx, der)
+	} else {
+		result, sig, err = EcdsaSignatureParseDER(ctx, der)
+	}
+	if err != nil {
+		return result, nil, err
+	}
+
+	if flags&(SignatureParseLowS|SignatureParseNormalize) != 0 {
+		normalized, lower, err := EcdsaSignatureNormalize(ctx, sig)
+		if err != nil {
+			return 0, nil, err
+		}
+		if normalized == 1 {
+			if flags&SignatureParseLowS != 0 {
+				return 0, nil, newError("EcdsaSignatureParse", 0, ErrorSignatureHighS)
+			}
+			sig = lower
+		}
+	}
+	return result, sig, nil
+}
+
+// EcdsaSignatureNormalize converts a signature to lower-S form. The return
+// code is 1 if the signature was not already in lower-S form, 0 otherwise;
+// the returned signature is in lower-S form in both cases.
+//
+// With ECDSA a third-party can forge a second distinct signature of the same
+// message, given a single initial signature, but without knowing the key.
+// This is done by negating the S value modulo the order of the curve. Since
+// EcdsaVerify only accepts lower-S signatures, signatures from sources that
+// do not obey this rule must be normalized before verification.
+func EcdsaSignatureNormalize(ctx *Context, sig *Signature) (int, *Signature, error) {
+	if sig == nil {
+		return 0, nil, newError("EcdsaSignatureNormalize", 0, ErrorSignatureNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdsaSignatureNormalize", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	out := newSignature()
+	result, err := call("secp256k1_ecdsa_signature_normalize", func() C.int {
+		return C.secp256k1_ecdsa_signature_normalize(ctx.ctx, out.sig, sig.sig)
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaSignatureNormalize", 0, err)
+	}
+	return result, out, nil
+}
+
+// EcdsaSignatureSerializeCompact serializes an ECDSA signature in compact
+// (64 bytes) format. The return code is always 1.
+func EcdsaSignatureSerializeCompact(ctx *Context, sig *Signature) (int, []byte, error) {
+	if sig == nil {
+		return 0, nil, newError("EcdsaSignatureSerializeCompact", 0, ErrorSignatureNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdsaSignatureSerializeCompact", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	output := make([]byte, LenCompactSignature)
+	result, err := call("secp256k1_ecdsa_signature_serialize_compact", func() C.int {
+		return C.secp256k1_ecdsa_signature_serialize_compact(ctx.ctx, cBuf(output), sig.sig)
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaSignatureSerializeCompact", 0, err)
+	}
+	return result, output, nil
+}
+
+// EcdsaSignatureSerializeDER serializes an ECDSA signature in DER format.
+// The return code is 1 if the signature was serialized, 0 otherwise. The
+// output is at most 72 bytes long.
+func EcdsaSignatureSerializeDER(ctx *Context, sig *Signature) (int, []byte, error) {
+	if sig == nil {
+		return 0, nil, newError("EcdsaSignatureSerializeDER", 0, ErrorSignatureNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdsaSignatureSerializeDER", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	output := make([]byte, LenMaxDERSignature)
+	outputLen := C.size_t(len(output))
+	result, err := call("secp256k1_ecdsa_signature_serialize_der", func() C.int {
+		return C.secp256k1_ecdsa_signature_serialize_der(ctx.ctx, cBuf(output), &outputLen, sig.sig)
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaSignatureSerializeDER", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("EcdsaSignatureSerializeDER", result, ErrorSignatureSerialize)
+	}
+	return result, output[:outputLen], nil
+}
+
+// EcdsaSign creates an ECDSA signature of the 32-byte message hash with the
+// given secret key. The nonce is derived deterministically following
+// RFC6979, and the produced signature is always in lower-S form. The
+// context must be initialized for signing. The return code is 1 if the
+// signature was created, 0 if the nonce generation failed or the secret key
+// was invalid.
+func EcdsaSign(ctx *Context, msg32 []byte, seckey []byte) (int, *Signature, error) {
+	if len(msg32) != LenMessageHash {
+		return 0, nil, newError("EcdsaSign", 0, ErrorMessageHashSize)
+	}
+	if len(seckey) != LenPrivateKey {
+		return 0, nil, newError("EcdsaSign", 0, ErrorPrivateKeySize)
+	}
+
+	if err := ctx.require(ContextSign); err != nil {
+		return 0, nil, newError("EcdsaSign", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newSignature()
+	result, err := call("secp256k1_ecdsa_sign", func() C.int {
+		return C.secp256k1_ecdsa_sign(ctx.ctx, sig.sig, cBuf(msg32), cBuf(seckey), nil, nil)
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaSign", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("EcdsaSign", result, ErrorSignatureCreate)
+	}
+	return result, sig, nil
+}
+
+// EcdsaVerify verifies an ECDSA signature of the 32-byte message hash with
+// the given public key. The context must be initialized for verification.
+// The return code is 1 for a correct signature, 0 for an incorrect or
+// unparseable signature, in which case no error is returned. Signatures
+// with a S value in the upper half of the order are rejected as well.
+func EcdsaVerify(ctx *Context, sig *Signature, msg32 []byte, publicKey *PublicKey) (int, error) {
+	if len(msg32) != LenMessageHash {
+		return 0, newError("EcdsaVerify", 0, ErrorMessageHashSize)
+	}
+	if sig == nil {
+		return 0, newError("EcdsaVerify", 0, ErrorSignatureNil)
+	}
+	if publicKey == nil {
+		return 0, newError("EcdsaVerify", 0, ErrorPublicKeyNil)
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, newError("EcdsaVerify", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_ecdsa_verify", func() C.int {
+		return C.secp256k1_ecdsa_verify(ctx.ctx, sig.sig, cBuf(msg32), publicKey.pk)
+	})
+	if err != nil {
+		return 0, newError("EcdsaVerify", 0, err)
+	}
+	return result, nil
+}
+
+// SerializeCompact returns the signature in compact (64 bytes) format
+func (sig *Signature) SerializeCompact() []byte {
+	_, bytes, _ := EcdsaSignatureSerializeCompact(SharedContext(ContextNone), sig)
+	return bytes
+}
+
+// SerializeDER returns the signature in DER format
+func (sig *Signature) SerializeDER() []byte {
+	_, bytes, _ := EcdsaSignatureSerializeDER(SharedContext(ContextNone), sig)
+	return bytes
+}
+
+// Normalize converts the signature to lower-S form in place, and reports
+// whether it was not already in lower-S form
+func (sig *Signature) Normalize() bool {
+	result, lower, err := EcdsaSignatureNormalize(SharedContext(ContextNone), sig)
+	if err != nil {
+		return false
+	}
+	*sig.sig = *lower.sig
+	return result == 1
+}
@@ -0,0 +1,196 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ecdsaParseTestVector struct {
+	DER     string `json:"der"`
+	Strict  bool   `json:"strict"`
+	Lax     bool   `json:"lax"`
+	HighS   bool   `json:"highS"`
+	Compact string `json:"compact"`
+}
+
+type ecdsaTestVector struct {
+	PrivKey string `json:"privKey"`
+	Msg     string `json:"msg"`
+	PubKey  string `json:"pubKey"`
+	Compact string `json:"compact"`
+	DER     string `json:"der"`
+}
+
+type ecdsaTestVectors struct {
+	Sign  []ecdsaTestVector      `json:"sign"`
+	Parse []ecdsaParseTestVector `json:"parse"`
+}
+
+func loadEcdsaTests(t *testing.T) ecdsaTestVectors {
+	file, err := ioutil.ReadFile("testdata/ecdsa.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests ecdsaTestVectors
+	if err := json.Unmarshal(file, &tests); err != nil {
+		t.Fatal(err)
+	}
+	return tests
+}
+
+func loadEcdsaVectors(t *testing.T) []ecdsaTestVector {
+	return loadEcdsaTests(t).Sign
+}
+
+func TestEcdsaSignAndVerify(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	for _, v := range loadEcdsaVectors(t) {
+		privKey, _ := hex.DecodeString(v.PrivKey)
+		msg, _ := hex.DecodeString(v.Msg)
+		pubKey, _ := hex.DecodeString(v.PubKey)
+
+		_, sig, err := EcdsaSign(ctx, msg, privKey)
+		assert.NoError(t, err)
+		assert.Equal(t, v.Compact, hex.EncodeToString(sig.SerializeCompact()))
+		assert.Equal(t, v.DER, hex.EncodeToString(sig.SerializeDER()))
+
+		_, pk, err := EcPubkeyParse(ctx, pubKey)
+		assert.NoError(t, err)
+		result, err := EcdsaVerify(ctx, sig, msg, pk)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+
+		msg[0] ^= 1
+		result, err = EcdsaVerify(ctx, sig, msg, pk)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, result)
+	}
+}
+
+func TestEcdsaSignatureParse(t *testing.T) {
+	ctx, _ := ContextCreate(ContextNone)
+	defer ContextDestroy(ctx)
+
+	for _, v := range loadEcdsaVectors(t) {
+		compact, _ := hex.DecodeString(v.Compact)
+		der, _ := hex.DecodeString(v.DER)
+
+		_, sig, err := EcdsaSignatureParseCompact(ctx, compact)
+		assert.NoError(t, err)
+		_, out, err := EcdsaSignatureSerializeDER(ctx, sig)
+		assert.NoError(t, err)
+		assert.Equal(t, der, out)
+
+		_, sig, err = EcdsaSignatureParseDER(ctx, der)
+		assert.NoError(t, err)
+		_, out, err = EcdsaSignatureSerializeCompact(ctx, sig)
+		assert.NoError(t, err)
+		assert.Equal(t, compact, out)
+	}
+
+	_, _, err := EcdsaSignatureParseCompact(ctx, make([]byte, 63))
+	assert.True(t, errors.Is(err, ErrorCompactSignatureSize))
+
+	overflow := make([]byte, 64)
+	for i := range overflow {
+		overflow[i] = 0xff
+	}
+	_, _, err = EcdsaSignatureParseCompact(ctx, overflow)
+	assert.True(t, errors.Is(err, ErrorSignatureParse))
+
+	_, _, err = EcdsaSignatureParseDER(ctx, []byte{0x30, 0x00})
+	assert.True(t, errors.Is(err, ErrorSignatureParse))
+}
+
+func TestEcdsaContextCapability(t *testing.T) {
+	v := loadEcdsaVectors(t)[0]
+	privKey, _ := hex.DecodeString(v.PrivKey)
+	msg, _ := hex.DecodeString(v.Msg)
+
+	verify, _ := ContextCreate(ContextVerify)
+	defer ContextDestroy(verify)
+	_, _, err := EcdsaSign(verify, msg, privKey)
+	assert.True(t, errors.Is(err, ErrContextCapability))
+
+	sign, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(sign)
+	_, sig, err := EcdsaSign(sign, msg, privKey)
+	assert.NoError(t, err)
+	_, pk, _ := EcPubkeyCreate(sign, privKey)
+	_, err = EcdsaVerify(sign, sig, msg, pk)
+	assert.True(t, errors.Is(err, ErrContextCapability))
+
+	_, _, err = EcdsaSign(sign, msg[:31], privKey)
+	assert.True(t, errors.Is(err, ErrorMessageHashSize))
+}
+
+func TestEcdsaSignatureParseLaxAndNormalize(t *testing.T) {
+	ctx, _ := ContextCreate(ContextVerify)
+	defer ContextDestroy(ctx)
+
+	tests := loadEcdsaTests(t)
+	for i, v := range tests.Parse {
+		// every signing vector has three parsing vectors
+		signVector := tests.Sign[i/3]
+		msg, _ := hex.DecodeString(signVector.Msg)
+		pubKey, _ := hex.DecodeString(signVector.PubKey)
+		_, pk, _ := EcPubkeyParse(ctx, pubKey)
+		der, _ := hex.DecodeString(v.DER)
+
+		_, _, err := EcdsaSignatureParseDER(ctx, der)
+		assert.Equal(t, v.Strict, err == nil)
+		_, _, err = EcdsaSignatureParse(ctx, der, SignatureParseStrict)
+		assert.Equal(t, v.Strict, err == nil)
+
+		_, sig, err := EcdsaSignatureParseDERLax(ctx, der)
+		assert.Equal(t, v.Lax, err == nil)
+		if !v.Lax {
+			continue
+		}
+
+		result, _ := EcdsaVerify(ctx, sig, msg, pk)
+		assert.Equal(t, !v.HighS, result == 1)
+		assert.Equal(t, v.HighS, sig.Normalize())
+		assert.Equal(t, v.Compact, hex.EncodeToString(sig.SerializeCompact()))
+		result, _ = EcdsaVerify(ctx, sig, msg, pk)
+		assert.Equal(t, 1, result)
+		assert.False(t, sig.Normalize())
+
+		_, sig, err = EcdsaSignatureParse(ctx, der, SignatureParseLax|SignatureParseNormalize)
+		assert.NoError(t, err)
+		assert.Equal(t, v.Compact, hex.EncodeToString(sig.SerializeCompact()))
+
+		_, _, err = EcdsaSignatureParse(ctx, der, SignatureParseLax|SignatureParseLowS)
+		assert.Equal(t, v.HighS, errors.Is(err, ErrorSignatureHighS))
+	}
+}
+
+func TestEcdsaNil(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	seckey := make([]byte, 32)
+	seckey[31] = 1
+	msg := make([]byte, 32)
+	_, pk, _ := EcPubkeyCreate(ctx, seckey)
+	_, sig, _ := EcdsaSign(ctx, msg, seckey)
+
+	_, err := EcdsaVerify(ctx, nil, msg, pk)
+	assert.True(t, errors.Is(err, ErrorSignatureNil))
+	_, err = EcdsaVerify(ctx, sig, msg, nil)
+	assert.True(t, errors.Is(err, ErrorPublicKeyNil))
+
+	_, _, err = EcdsaSignatureNormalize(ctx, nil)
+	assert.True(t, errors.Is(err, ErrorSignatureNil))
+	_, _, err = EcdsaSignatureSerializeCompact(ctx, nil)
+	assert.True(t, errors.Is(err, ErrorSignatureNil))
+	_, _, err = EcdsaSignatureSerializeDER(ctx, nil)
+	assert.True(t, errors.Is(err, ErrorSignatureNil))
+}
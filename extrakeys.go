@@ -0,0 +1,84 @@
+package secp256k1
+
+import (
+	"errors"
+)
+
+const (
+	// Length of elements byte representations
+	LenXOnlyPublicKey int = 32
+)
+
+var (
+	ErrorXOnlyPublicKeySize = errors.New("x-only public key must be exactly 32 bytes")
+)
+
+// XOnlyPublicKey is a public key identified by its X coordinate only, as
+// defined by BIP340. Of the two points with the same X coordinate, it is the
+// one with even Y. It wraps a *PublicKey that always holds such point.
+type XOnlyPublicKey struct {
+	pk *PublicKey
+}
+
+// XOnlyPubkeyParse parses a 32-byte x-only public key, the big endian X
+// coordinate of the point. The return code is 1 if the public key is valid,
+// 0 if it is not the X coordinate of a point of the curve.
+func XOnlyPubkeyParse(ctx *Context, input32 []byte) (int, *XOnlyPublicKey, error) {
+	if len(input32) != LenXOnlyPublicKey {
+		return 0, nil, newError("XOnlyPubkeyParse", 0, ErrorXOnlyPublicKeySize)
+	}
+
+	compressed := make([]byte, LenCompressed)
+	compressed[0] = 0x02
+	copy(compressed[1:], input32)
+	result, pk, err := EcPubkeyParse(ctx, compressed)
+	if err != nil {
+		return result, nil, err
+	}
+	return result, &XOnlyPublicKey{pk}, nil
+}
+
+// XOnlyPubkeySerialize serializes an x-only public key into 32 bytes. The
+// return code is always 1.
+func XOnlyPubkeySerialize(ctx *Context, xonly *XOnlyPublicKey) (int, []byte, error) {
+	result, compressed, err := EcPubkeySerialize(ctx, xonly.pk, EcCompressed)
+	if err != nil {
+		return result, nil, err
+	}
+	return result, compressed[1:], nil
+}
+
+// XOnlyPubkeyFromPubkey converts a public key into the x-only public key
+// with the same X coordinate. The parity is 1 if the public key has odd Y,
+// i.e. the x-only public key is its negation, 0 otherwise. The return code
+// is always 1.
+func XOnlyPubkeyFromPubkey(ctx *Context, publicKey *PublicKey) (result int, xonly *XOnlyPublicKey, parity int, err error) {
+	result, compressed, err := EcPubkeySerialize(ctx, publicKey, EcCompressed)
+	if err != nil {
+		return
+	}
+
+	pk := newPublicKey()
+	*pk.pk = *publicKey.pk
+	if compressed[0] == 0x03 {
+		parity = 1
+		if result, err = EcPubKeyNegate(ctx, pk); err != nil {
+			return
+		}
+	}
+	xonly = &XOnlyPublicKey{pk}
+	return
+}
+
+// Serialize returns the x-only public key serialized into 32 bytes
+func (xonly *XOnlyPublicKey) Serialize() []byte {
+	_, bytes, _ := XOnlyPubkeySerialize(SharedContext(ContextNone), xonly)
+	return bytes
+}
+
+// PublicKey returns the full public key, the point with even Y
+func (xonly *XOnlyPublicKey) PublicKey() *PublicKey {
+	pk := newPublicKey()
+	*pk.pk = *xonly.pk.pk
+	return pk
+}
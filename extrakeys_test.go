@@ -0,0 +1,56 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXOnlyPubkeyParseAndSerialize(t *testing.T) {
+	ctx, _ := ContextCreate(ContextNone)
+	defer ContextDestroy(ctx)
+
+	input, _ := hex.DecodeString("dff1d77f2a671c5f36183726db2341be58feae1da2deced843240f7b502ba659")
+	_, xonly, err := XOnlyPubkeyParse(ctx, input)
+	assert.NoError(t, err)
+	_, output, err := XOnlyPubkeySerialize(ctx, xonly)
+	assert.NoError(t, err)
+	assert.Equal(t, input, output)
+	assert.Equal(t, input, xonly.Serialize())
+
+	_, compressed, _ := EcPubkeySerialize(ctx, xonly.PublicKey(), EcCompressed)
+	assert.Equal(t, append([]byte{0x02}, input...), compressed)
+
+	// not the X coordinate of a point of the curve
+	input, _ = hex.DecodeString("eefdea4cdb677750a420fee807eacf21eb9898ae79b9768766e4faa04a2d4a34")
+	_, _, err = XOnlyPubkeyParse(ctx, input)
+	assert.True(t, errors.Is(err, ErrorPublicKeyParse))
+
+	_, _, err = XOnlyPubkeyParse(ctx, make([]byte, 33))
+	assert.True(t, errors.Is(err, ErrorXOnlyPublicKeySize))
+}
+
+func TestXOnlyPubkeyFromPubkey(t *testing.T) {
+	ctx, _ := ContextCreate(ContextNone)
+	defer ContextDestroy(ctx)
+
+	x := "79be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	for prefix, parity := range map[string]int{"02": 0, "03": 1} {
+		input, _ := hex.DecodeString(prefix + x)
+		_, pk, _ := EcPubkeyParse(ctx, input)
+
+		_, xonly, p, err := XOnlyPubkeyFromPubkey(ctx, pk)
+		assert.NoError(t, err)
+		assert.Equal(t, parity, p)
+		assert.Equal(t, x, hex.EncodeToString(xonly.Serialize()))
+
+		// the x-only public key always holds the point with even Y, while
+		// the public key is left untouched
+		_, compressed, _ := EcPubkeySerialize(ctx, xonly.PublicKey(), EcCompressed)
+		assert.Equal(t, "02"+x, hex.EncodeToString(compressed))
+		_, compressed, _ = EcPubkeySerialize(ctx, pk, EcCompressed)
+		assert.Equal(t, prefix+x, hex.EncodeToString(compressed))
+	}
+}
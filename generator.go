@@ -9,6 +9,7 @@ import "C"
 import (
 	"encoding/hex"
 	"errors"
+	"runtime"
 )
 
 // Generator contains a pointer to opaque data structure that stores a base point
@@ -21,9 +22,9 @@ type Generator struct {
 	gen *C.secp256k1_generator
 }
 
-const (
-	ErrGeneratorParse    string = "failed to parse data as a generator"
-	ErrGeneratorGenerate string = "failed to create a generator"
+var (
+	ErrGeneratorParse    = errors.New("failed to parse data as a generator")
+	ErrGeneratorGenerate = errors.New("failed to create a generator")
 )
 
 var (
@@ -50,18 +51,28 @@ func GeneratorParse(
 	err error,
 ) {
 	if 33 != len(bytes) {
-		return nil, errors.New(ErrGeneratorParse + " (invalid length)")
+		return nil, newError("GeneratorParse", 0, ErrGeneratorParse)
 	}
 	if context == nil {
 		context = SharedContext(ContextNone)
 	}
-	generator = newGenerator()
-	if 1 != C.secp256k1_generator_parse(
-		context.ctx,
-		generator.gen,
-		cBuf(bytes)) {
+	if err := context.check(); err != nil {
+		return nil, newError("GeneratorParse", 0, err)
+	}
+	defer runtime.KeepAlive(context)
 
-		return nil, errors.New(ErrGeneratorParse)
+	generator = newGenerator()
+	result, err := call("secp256k1_generator_parse", func() C.int {
+		return C.secp256k1_generator_parse(
+			context.ctx,
+			generator.gen,
+			cBuf(bytes))
+	})
+	if err != nil {
+		return nil, newError("GeneratorParse", 0, err)
+	}
+	if 1 != result {
+		return nil, newError("GeneratorParse", result, ErrGeneratorParse)
 	}
 
 	return
@@ -71,6 +82,7 @@ func GeneratorParse(
 //  -> context   non-NULL context
 //  -> generator generator object
 //  <- bytes     33 bytes of data
+// It panics with a *CallbackError if the generator is not initialized.
 func GeneratorSerialize(
 	context *Context,
 	generator *Generator,
@@ -80,10 +92,17 @@ func GeneratorSerialize(
 	if context == nil {
 		context = SharedContext(ContextNone)
 	}
-	C.secp256k1_generator_serialize(
-		context.ctx,
-		cBuf(bytes[:]),
-		generator.gen)
+	if context.check() != nil {
+		return
+	}
+	defer runtime.KeepAlive(context)
+
+	mustCall("secp256k1_generator_serialize", func() C.int {
+		return C.secp256k1_generator_serialize(
+			context.ctx,
+			cBuf(bytes[:]),
+			generator.gen)
+	})
 
 	return
 }
@@ -137,13 +156,23 @@ func GeneratorGenerate(ctx *Context, seed []byte) (*Generator, error) {
 	if ctx == nil {
 		ctx = SharedContext(ContextSign)
 	}
-	generator := newGenerator()
-	if 1 != C.secp256k1_generator_generate(
-		ctx.ctx,
-		generator.gen,
-		cBuf(seed)) {
+	if err := ctx.check(); err != nil {
+		return nil, newError("GeneratorGenerate", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
 
-		return nil, errors.New(ErrGeneratorGenerate)
+	generator := newGenerator()
+	result, err := call("secp256k1_generator_generate", func() C.int {
+		return C.secp256k1_generator_generate(
+			ctx.ctx,
+			generator.gen,
+			cBuf(seed))
+	})
+	if err != nil {
+		return nil, newError("GeneratorGenerate", 0, err)
+	}
+	if 1 != result {
+		return nil, newError("GeneratorGenerate", result, ErrGeneratorGenerate)
 	}
 
 	return generator, nil
@@ -167,14 +196,24 @@ func GeneratorGenerateBlinded(ctx *Context, seed []byte, blind []byte) (*Generat
 	if ctx == nil {
 		ctx = SharedContext(ContextSign)
 	}
+	if err := ctx.require(ContextSign); err != nil {
+		return nil, newError("GeneratorGenerateBlinded", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
 	generator := newGenerator()
-	if 1 != int(
-		C.secp256k1_generator_generate_blinded(
+	result, err := call("secp256k1_generator_generate_blinded", func() C.int {
+		return C.secp256k1_generator_generate_blinded(
 			ctx.ctx,
 			generator.gen,
 			cBuf(seed),
-			cBuf(blind))) {
-		return nil, errors.New(ErrGeneratorGenerate)
+			cBuf(blind))
+	})
+	if err != nil {
+		return nil, newError("GeneratorGenerateBlinded", 0, err)
+	}
+	if 1 != result {
+		return nil, newError("GeneratorGenerateBlinded", result, ErrGeneratorGenerate)
 	}
 	return generator, nil
 }
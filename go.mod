@@ -1,5 +1,14 @@
 module github.com/vulpemventures/go-secp256k1-zkp
 
-go 1.13
+go 1.26.0
 
-require github.com/stretchr/testify v1.5.1
+require (
+	github.com/stretchr/testify v1.5.1
+	golang.org/x/crypto v0.57.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	gopkg.in/yaml.v2 v2.2.2 // indirect
+)
@@ -0,0 +1,19 @@
+package secp256k1
+
+import (
+	"crypto/sha256"
+
+	// ripemd160 is deprecated upstream as too weak for new designs, but
+	// HASH160 is defined by Bitcoin as RIPEMD-160 over SHA-256, so there is
+	// no substitute.
+	"golang.org/x/crypto/ripemd160" //nolint:staticcheck // required by HASH160
+)
+
+// hash160 returns RIPEMD160(SHA256(data)), the hash committed to by P2PKH
+// and P2SH scripts.
+func hash160(data []byte) []byte {
+	hash := sha256.Sum256(data)
+	h := ripemd160.New()
+	h.Write(hash[:])
+	return h.Sum(nil)
+}
@@ -0,0 +1,15 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHash160(t *testing.T) {
+	// the compressed generator, whose Hash160 is the one of the well known
+	// address 1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH
+	pubkey, _ := hex.DecodeString("0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798")
+	assert.Equal(t, "751e76e8199196d454941c45d1b3a323f1433bd6", hex.EncodeToString(hash160(pubkey)))
+}
@@ -0,0 +1,138 @@
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+)
+
+const (
+	// Length of elements byte representations
+	LenTxid         int = 32
+	LenContractHash int = 32
+)
+
+var (
+	ErrorTxidSize           = errors.New("txid must be exactly 32 bytes")
+	ErrorContractHashSize   = errors.New("contract hash must be empty or exactly 32 bytes")
+	ErrorAssetEntropyNil    = errors.New("asset entropy is nil")
+	ErrorDisplayHexSize     = errors.New("display hex must encode exactly 32 bytes")
+	ErrorDisplayHexEncoding = errors.New("invalid display hex encoding")
+)
+
+// The right leaf of the hash of a reissuance token depends on whether the
+// issuance amount is explicit or confidential
+const (
+	reissuanceTokenExplicit     byte = 1
+	reissuanceTokenConfidential byte = 2
+)
+
+// IssuanceEntropy returns the entropy of an asset issuance spending the
+// outpoint txid:vout, committing to the hash of the issuance contract.
+// Both the txid and the contract hash are in internal byte order, see
+// DisplayHexDecode to use the ones displayed by explorers and by the RPCs of
+// Elements Core. An empty contract hash means no contract, as in Elements.
+func IssuanceEntropy(txid []byte, vout uint32, contractHash []byte) (*FixedAssetTag, error) {
+	if len(txid) != LenTxid {
+		return nil, newError("IssuanceEntropy", 0, ErrorTxidSize)
+	}
+	if len(contractHash) != 0 && len(contractHash) != LenContractHash {
+		return nil, newError("IssuanceEntropy", 0, ErrorContractHashSize)
+	}
+
+	outpoint := make([]byte, LenTxid+4)
+	copy(outpoint, txid)
+	binary.LittleEndian.PutUint32(outpoint[LenTxid:], vout)
+	first := sha256.Sum256(outpoint)
+	outpointHash := sha256.Sum256(first[:])
+
+	var contract [32]byte
+	copy(contract[:], contractHash)
+	return FixedAssetTagParse(fastMerkleHash(outpointHash[:], contract[:]))
+}
+
+// AssetFromEntropy returns the ID of the asset issued with entropy
+func AssetFromEntropy(entropy *FixedAssetTag) (*FixedAssetTag, error) {
+	if entropy == nil {
+		return nil, newError("AssetFromEntropy", 0, ErrorAssetEntropyNil)
+	}
+	return FixedAssetTagParse(fastMerkleHash(entropy.Slice(), make([]byte, 32)))
+}
+
+// ReissuanceTokenFromEntropy returns the ID of the reissuance token of the
+// asset issued with entropy, which depends on whether the amount of the
+// issuance is confidential or explicit.
+func ReissuanceTokenFromEntropy(entropy *FixedAssetTag, confidential bool) (*FixedAssetTag, error) {
+	if entropy == nil {
+		return nil, newError("ReissuanceTokenFromEntropy", 0, ErrorAssetEntropyNil)
+	}
+	token := make([]byte, 32)
+	token[0] = reissuanceTokenExplicit
+	if confidential {
+		token[0] = reissuanceTokenConfidential
+	}
+	return FixedAssetTagParse(fastMerkleHash(entropy.Slice(), token))
+}
+
+// fastMerkleHash is the inner node hash of the fast Merkle roots Elements
+// derives issued assets with: the SHA256 midstate, without length padding,
+// after compressing the single block left||right of two 32 bytes leaves.
+func fastMerkleHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write(left)
+	h.Write(right)
+	// the binary state of a digest holding no buffered data is its 4 bytes
+	// magic followed by the big endian words of the midstate. States must
+	// stay readable by later Go releases, so this layout does not change;
+	// TestFastMerkleHash checks it.
+	state, _ := h.(encoding.BinaryMarshaler).MarshalBinary()
+	return state[4 : 4+sha256.Size]
+}
+
+// DisplayHexDecode decodes a 32 bytes hash, such as a txid, a contract hash
+// or an asset ID, from the reversed hex encoding used by explorers and by
+// Elements Core, into internal byte order.
+func DisplayHexDecode(str string) ([]byte, error) {
+	b, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, newError("DisplayHexDecode", 0, ErrorDisplayHexEncoding)
+	}
+	if len(b) != 32 {
+		return nil, newError("DisplayHexDecode", 0, ErrorDisplayHexSize)
+	}
+	return reverseBytes(b), nil
+}
+
+// DisplayHexEncode encodes a hash in internal byte order with the reversed
+// hex encoding used by explorers and by Elements Core
+func DisplayHexEncode(b []byte) string {
+	return hex.EncodeToString(reverseBytes(b))
+}
+
+// FixedAssetTagFromDisplayHex parses an asset ID as displayed by explorers
+// and by Elements Core, unlike FixedAssetTagFromHex which expects the
+// internal byte order.
+func FixedAssetTagFromDisplayHex(str string) (*FixedAssetTag, error) {
+	b, err := DisplayHexDecode(str)
+	if err != nil {
+		return nil, err
+	}
+	return FixedAssetTagParse(b)
+}
+
+// DisplayHex converts a fixed asset tag to the reversed hex encoding of asset
+// IDs used by explorers and by Elements Core, unlike Hex which keeps the
+// internal byte order.
+func (asset *FixedAssetTag) DisplayHex() string {
+	return DisplayHexEncode(asset.Slice())
+}
+
+func reverseBytes(b []byte) []byte {
+	out := make([]byte, len(b))
+	for i := range b {
+		out[len(b)-1-i] = b[i]
+	}
+	return out
+}
@@ -0,0 +1,124 @@
+package secp256k1
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssuance(t *testing.T) {
+	file, err := ioutil.ReadFile("testdata/issuance.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests struct {
+		Issuance []struct {
+			Txid              string `json:"txid"`
+			Vout              uint32 `json:"vout"`
+			ContractHash      string `json:"contractHash"`
+			Entropy           string `json:"entropy"`
+			Asset             string `json:"asset"`
+			Token             string `json:"token"`
+			ConfidentialToken string `json:"confidentialToken"`
+		} `json:"issuance"`
+	}
+	if err := json.Unmarshal(file, &tests); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range tests.Issuance {
+		txid, err := DisplayHexDecode(v.Txid)
+		assert.NoError(t, err)
+		contractHash, err := DisplayHexDecode(v.ContractHash)
+		assert.NoError(t, err)
+
+		entropy, err := IssuanceEntropy(txid, v.Vout, contractHash)
+		if !assert.NoError(t, err) {
+			continue
+		}
+		assert.Equal(t, v.Entropy, entropy.DisplayHex())
+
+		asset, err := AssetFromEntropy(entropy)
+		assert.NoError(t, err)
+		assert.Equal(t, v.Asset, asset.DisplayHex())
+
+		token, err := ReissuanceTokenFromEntropy(entropy, false)
+		assert.NoError(t, err)
+		assert.Equal(t, v.Token, token.DisplayHex())
+
+		confidentialToken, err := ReissuanceTokenFromEntropy(entropy, true)
+		assert.NoError(t, err)
+		assert.Equal(t, v.ConfidentialToken, confidentialToken.DisplayHex())
+	}
+}
+
+func TestFastMerkleHash(t *testing.T) {
+	// the marshaled state starts with the magic of SHA256 digests
+	state, err := sha256.New().(encoding.BinaryMarshaler).MarshalBinary()
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("sha\x03"), state[:4])
+
+	// the fast Merkle hash of two zero leaves is the bare midstate, without
+	// any padding
+	assert.Equal(t, "da5698be17b9b46962335799779fbeca8ce5d491c0d26243bafef9ea1837a9d8",
+		hex.EncodeToString(fastMerkleHash(make([]byte, 32), make([]byte, 32))))
+}
+
+func TestIssuanceEntropyNoContract(t *testing.T) {
+	txid := make([]byte, 32)
+	txid[0] = 1
+
+	withoutContract, err := IssuanceEntropy(txid, 1, nil)
+	assert.NoError(t, err)
+	withZeroContract, err := IssuanceEntropy(txid, 1, make([]byte, 32))
+	assert.NoError(t, err)
+	assert.Equal(t, withZeroContract.Hex(), withoutContract.Hex())
+
+	otherVout, err := IssuanceEntropy(txid, 2, nil)
+	assert.NoError(t, err)
+	assert.NotEqual(t, withoutContract.Hex(), otherVout.Hex())
+}
+
+func TestIssuanceInvalid(t *testing.T) {
+	_, err := IssuanceEntropy(make([]byte, 31), 0, nil)
+	assert.True(t, errors.Is(err, ErrorTxidSize))
+	_, err = IssuanceEntropy(make([]byte, 32), 0, make([]byte, 31))
+	assert.True(t, errors.Is(err, ErrorContractHashSize))
+
+	_, err = AssetFromEntropy(nil)
+	assert.True(t, errors.Is(err, ErrorAssetEntropyNil))
+	_, err = ReissuanceTokenFromEntropy(nil, true)
+	assert.True(t, errors.Is(err, ErrorAssetEntropyNil))
+}
+
+func TestDisplayHex(t *testing.T) {
+	// L-BTC, the policy asset of Liquid
+	display := "6f0279e9ed041c3d710a9f57d0c02928416460c4b722ae3457a11eec381c526d"
+	internal := "6d521c38ec1ea15734ae22b7c46064412829c0d0579f0a713d1c04ede979026f"
+
+	b, err := DisplayHexDecode(display)
+	assert.NoError(t, err)
+	assert.Equal(t, internal, hex.EncodeToString(b))
+	assert.Equal(t, display, DisplayHexEncode(b))
+
+	asset, err := FixedAssetTagFromDisplayHex(display)
+	assert.NoError(t, err)
+	assert.Equal(t, internal, asset.Hex())
+	assert.Equal(t, display, asset.DisplayHex())
+
+	fromHex, _ := FixedAssetTagFromHex(internal)
+	assert.Equal(t, display, fromHex.DisplayHex())
+
+	_, err = DisplayHexDecode(display[:62])
+	assert.True(t, errors.Is(err, ErrorDisplayHexSize))
+	_, err = FixedAssetTagFromDisplayHex(display + "00")
+	assert.True(t, errors.Is(err, ErrorDisplayHexSize))
+	_, err = FixedAssetTagFromDisplayHex("z" + display[1:])
+	assert.True(t, errors.Is(err, ErrorDisplayHexEncoding))
+}
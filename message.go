@@ -0,0 +1,118 @@
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// MessageMagic is the prefix prepended to a message before hashing it,
+	// the same used by Bitcoin and Elements for the signed message format
+	MessageMagic = "Bitcoin Signed Message:\n"
+
+	// messageHeaderBase is the header byte of a signature for an
+	// uncompressed public key, to which the recovery id is added
+	messageHeaderBase = 27
+	// messageHeaderCompressed is added to the header byte of a signature
+	// for a compressed public key
+	messageHeaderCompressed = 4
+)
+
+var (
+	ErrorMessageSignatureEncoding = errors.New("message signature must be base64 encoded")
+	ErrorMessageSignatureHeader   = errors.New("invalid message signature header byte")
+)
+
+// MessageHash returns the hash signed by SignMessage, the double SHA256 of
+// the varint length prefixed MessageMagic followed by the varint length
+// prefixed message.
+func MessageHash(message string) []byte {
+	var buf bytes.Buffer
+	writeVarString(&buf, MessageMagic)
+	writeVarString(&buf, message)
+
+	first := sha256.Sum256(buf.Bytes())
+	hash := sha256.Sum256(first[:])
+	return hash[:]
+}
+
+// writeVarString writes the length of s, encoded as a Bitcoin compact size
+// unsigned integer, followed by s.
+func writeVarString(buf *bytes.Buffer, s string) {
+	var size [9]byte
+	l := uint64(len(s))
+	switch {
+	case l < 0xfd:
+		buf.WriteByte(byte(l))
+	case l <= 0xffff:
+		size[0] = 0xfd
+		binary.LittleEndian.PutUint16(size[1:], uint16(l))
+		buf.Write(size[:3])
+	case l <= 0xffffffff:
+		size[0] = 0xfe
+		binary.LittleEndian.PutUint32(size[1:], uint32(l))
+		buf.Write(size[:5])
+	default:
+		size[0] = 0xff
+		binary.LittleEndian.PutUint64(size[1:], l)
+		buf.Write(size[:])
+	}
+	buf.WriteString(s)
+}
+
+// SignMessage signs a message with the given secret key, in the format used
+// by the signmessage RPC of Bitcoin and Elements. The signature is the base64
+// encoding of 65 bytes: a header byte, set to 31 plus the recovery id since
+// the public key is meant to be serialized compressed, followed by the
+// compact signature of MessageHash(message). The context must be initialized
+// for signing.
+func SignMessage(ctx *Context, seckey []byte, message string) (string, error) {
+	_, sig, err := EcdsaSignRecoverable(ctx, MessageHash(message), seckey)
+	if err != nil {
+		return "", err
+	}
+	_, compact, err := EcdsaRecoverableSignatureSerializeCompact(ctx, sig)
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]byte, LenCompactRecoverableSignature)
+	out[0] = byte(messageHeaderBase + messageHeaderCompressed + int(compact[LenCompactSignature]))
+	copy(out[1:], compact[:LenCompactSignature])
+	return base64.StdEncoding.EncodeToString(out), nil
+}
+
+// VerifyMessage recovers the public key that signed a message from a
+// signature in the format produced by SignMessage. The returned flag reports
+// whether the signer used the compressed serialization of the public key,
+// which must be taken into account when comparing it to an address. The
+// context must be initialized for verification.
+func VerifyMessage(ctx *Context, message string, signature string) (*PublicKey, bool, error) {
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) != LenCompactRecoverableSignature {
+		return nil, false, newError("VerifyMessage", 0, ErrorMessageSignatureEncoding)
+	}
+
+	header := int(sig[0]) - messageHeaderBase
+	if header < 0 || header > 7 {
+		return nil, false, newError("VerifyMessage", 0, ErrorMessageSignatureHeader)
+	}
+	compressed := header&messageHeaderCompressed != 0
+
+	compact := make([]byte, LenCompactRecoverableSignature)
+	copy(compact, sig[1:])
+	compact[LenCompactSignature] = byte(header & 3)
+
+	_, recoverable, err := EcdsaRecoverableSignatureParseCompact(ctx, compact)
+	if err != nil {
+		return nil, false, err
+	}
+	_, pk, err := EcdsaRecover(ctx, recoverable, MessageHash(message))
+	if err != nil {
+		return nil, false, err
+	}
+	return pk, compressed, nil
+}
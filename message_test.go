@@ -0,0 +1,82 @@
+package secp256k1
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignAndVerifyMessage(t *testing.T) {
+	file, err := ioutil.ReadFile("testdata/message.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testVectorType struct {
+		PrivKey   string `json:"privKey"`
+		Message   string `json:"message"`
+		Hash      string `json:"hash"`
+		PubKey    string `json:"pubKey"`
+		Signature string `json:"signature"`
+	}
+	type testType struct {
+		Vectors []testVectorType `json:"signMessage"`
+	}
+
+	var test testType
+	json.Unmarshal(file, &test)
+
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	for _, v := range test.Vectors {
+		privKey, _ := hex.DecodeString(v.PrivKey)
+		assert.Equal(t, v.Hash, hex.EncodeToString(MessageHash(v.Message)))
+
+		signature, err := SignMessage(ctx, privKey, v.Message)
+		assert.NoError(t, err)
+		assert.Equal(t, v.Signature, signature)
+
+		pk, compressed, err := VerifyMessage(ctx, v.Message, v.Signature)
+		assert.NoError(t, err)
+		assert.True(t, compressed)
+		_, pkBytes, _ := EcPubkeySerialize(ctx, pk, EcCompressed)
+		assert.Equal(t, v.PubKey, hex.EncodeToString(pkBytes))
+
+		// the same signature for an uncompressed key
+		sig, _ := base64.StdEncoding.DecodeString(v.Signature)
+		sig[0] -= 4
+		pk, compressed, err = VerifyMessage(ctx, v.Message, base64.StdEncoding.EncodeToString(sig))
+		assert.NoError(t, err)
+		assert.False(t, compressed)
+		_, pkBytes, _ = EcPubkeySerialize(ctx, pk, EcCompressed)
+		assert.Equal(t, v.PubKey, hex.EncodeToString(pkBytes))
+
+		pk, _, err = VerifyMessage(ctx, v.Message+".", v.Signature)
+		if err == nil {
+			_, pkBytes, _ = EcPubkeySerialize(ctx, pk, EcCompressed)
+			assert.NotEqual(t, v.PubKey, hex.EncodeToString(pkBytes))
+		}
+	}
+}
+
+func TestVerifyMessageInvalidSignature(t *testing.T) {
+	ctx, _ := ContextCreate(ContextVerify)
+	defer ContextDestroy(ctx)
+
+	_, _, err := VerifyMessage(ctx, "message", "not base64!")
+	assert.True(t, errors.Is(err, ErrorMessageSignatureEncoding))
+
+	_, _, err = VerifyMessage(ctx, "message", base64.StdEncoding.EncodeToString(make([]byte, 64)))
+	assert.True(t, errors.Is(err, ErrorMessageSignatureEncoding))
+
+	sig := make([]byte, 65)
+	sig[0] = 35
+	_, _, err = VerifyMessage(ctx, "message", base64.StdEncoding.EncodeToString(sig))
+	assert.True(t, errors.Is(err, ErrorMessageSignatureHeader))
+}
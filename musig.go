@@ -0,0 +1,724 @@
+package secp256k1
+
+/*
+#include <stdlib.h>
+#include "include/secp256k1.h"
+#include "include/secp256k1_schnorrsig.h"
+#include "include/secp256k1_musig.h"
+#cgo CFLAGS: -I${SRCDIR}/secp256k1-zkp -I${SRCDIR}/secp256k1-zkp/src
+static const unsigned char** makeNonceCommitmentsArray(unsigned char* buf, int size) {
+	const unsigned char** a;
+	int i;
+	if (!size) return NULL;
+	a = calloc(sizeof(unsigned char*), size);
+	if (a) for (i = 0; i < size; i++) a[i] = buf + 32 * i;
+	return a;
+}
+static void freeNonceCommitmentsArray(const unsigned char** a) { if (a) free(a); }
+*/
+import "C"
+
+import (
+	"errors"
+	"runtime"
+)
+
+const (
+	// Length of elements byte representations
+	LenMusigPartialSignature int = 32
+	LenNonceCommitment       int = 32
+	LenSessionID             int = 32
+	LenPublicKeyHash         int = 32
+)
+
+var (
+	ErrorMusigPartialSignatureSize  = errors.New("musig partial signature must be exactly 32 bytes")
+	ErrorMusigPartialSignatureParse = errors.New("unable to parse the musig partial signature")
+	ErrorMusigPartialSignatureNil   = errors.New("musig partial signature is nil")
+	ErrorNonceCommitmentSize        = errors.New("nonce commitment must be exactly 32 bytes")
+	ErrorSessionIDSize              = errors.New("session id must be exactly 32 bytes")
+	ErrorPublicKeyHashSize          = errors.New("public key hash must be exactly 32 bytes")
+	ErrorMusigPubkeyCombine         = errors.New("unable to combine the musig public keys")
+	ErrorMusigSessionInitialize     = errors.New("unable to initialize the musig session")
+	ErrorMusigSignerIndex           = errors.New("musig signer index out of range")
+	ErrorMusigSignerCount           = errors.New("number of musig signers differs from the session")
+	ErrorMusigRound                 = errors.New("musig session is not in the expected round")
+	ErrorMusigVerifierSession       = errors.New("musig verifier session cannot sign")
+	ErrorMusigPublicNonce           = errors.New("nonce commitments differ from the ones already received")
+	ErrorMusigNonceCommitment       = errors.New("musig nonce does not match its commitment")
+	ErrorMusigNonceCombine          = errors.New("unable to combine the musig nonces")
+	ErrorMusigPartialSign           = errors.New("unable to create the musig partial signature")
+	ErrorMusigPartialSigCombine     = errors.New("unable to combine the musig partial signatures")
+	ErrorMusigPartialSigAdapt       = errors.New("unable to adapt the musig partial signature")
+	ErrorMusigExtractSecretAdaptor  = errors.New("unable to extract the secret adaptor")
+)
+
+// MusigRound is the last round completed by a MuSig session. The rounds
+// follow one another in the order they are declared.
+type MusigRound int
+
+const (
+	// MusigRoundCommitment is the round of a session just initialized for
+	// signing, whose nonce commitment must be sent to the other signers
+	MusigRoundCommitment MusigRound = iota + 1
+	// MusigRoundNonce is the round of a session that received all the nonce
+	// commitments, so that its public nonce can be sent to the other signers
+	// and their nonces can be set. Verifier sessions start from this round.
+	MusigRoundNonce
+	// MusigRoundCombined is the round of a session whose nonces have been
+	// combined, ready to create and verify partial signatures
+	MusigRoundCombined
+	// MusigRoundSigned is the round of a session that created its partial
+	// signature, after which its secret nonce is cleared
+	MusigRoundSigned
+)
+
+// MusigPartialSignature wraps a *secp256k1_musig_partial_signature, the
+// partial signature of one of the signers of a MuSig session.
+type MusigPartialSignature struct {
+	sig *C.secp256k1_musig_partial_signature
+}
+
+func newMusigPartialSignature() *MusigPartialSignature {
+	return &MusigPartialSignature{
+		sig: &C.secp256k1_musig_partial_signature{},
+	}
+}
+
+// MusigSession holds the state of a MuSig signing session, as seen by one of
+// the signers or by a verifier. It walks through the rounds of the protocol,
+// each method checking that the session is in the expected round. A session
+// must not be used concurrently by several goroutines.
+type MusigSession struct {
+	session *C.secp256k1_musig_session
+	signers []C.secp256k1_musig_session_signer_data
+	round   MusigRound
+}
+
+// MusigPubkeyCombine computes the combined public key of the given public
+// keys, whose order matters, and the 32-byte hash of the public keys needed
+// to initialize a session. The context must be initialized for verification.
+// If scratch is not nil it is used to speed up the computation for a large
+// number of keys. The return code is 1 if the public keys were combined, 0
+// otherwise.
+func MusigPubkeyCombine(ctx *Context, scratch *ScratchSpace, publicKeys []*PublicKey) (int, *PublicKey, []byte, error) {
+	n := len(publicKeys)
+	if n < 1 {
+		return 0, nil, nil, newError("MusigPubkeyCombine", 0, ErrorPublicKeyCount)
+	}
+	pks := make([]C.secp256k1_pubkey, n)
+	for i, pk := range publicKeys {
+		if pk == nil {
+			return 0, nil, nil, newIndexError("MusigPubkeyCombine", i, ErrorPublicKeyNil)
+		}
+		pks[i] = *pk.pk
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, nil, nil, newError("MusigPubkeyCombine", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	var cScratch *C.secp256k1_scratch_space
+	if scratch != nil {
+		if err := scratch.check(); err != nil {
+			return 0, nil, nil, newError("MusigPubkeyCombine", 0, err)
+		}
+		defer runtime.KeepAlive(scratch)
+		cScratch = scratch.scratch
+	}
+
+	combined := newPublicKey()
+	pkHash := make([]byte, LenPublicKeyHash)
+	result, err := call("secp256k1_musig_pubkey_combine", func() C.int {
+		return C.secp256k1_musig_pubkey_combine(ctx.ctx, cScratch, combined.pk, cBuf(pkHash), &pks[0], C.size_t(n))
+	})
+	if err != nil {
+		return 0, nil, nil, newError("MusigPubkeyCombine", 0, err)
+	}
+	if result != 1 {
+		return result, nil, nil, newError("MusigPubkeyCombine", result, ErrorMusigPubkeyCombine)
+	}
+	return result, combined, pkHash, nil
+}
+
+// MusigSessionInitialize initializes a signing session for the signer at
+// myIndex among nSigners, for the 32-byte message hash and the combined
+// public key and public key hash returned by MusigPubkeyCombine. The
+// sessionID32 must be unique for every session: reusing it for another
+// message LEAKS THE SECRET KEY, so it should be 32 fresh random bytes. The
+// returned nonce commitment must be sent to the other signers. The context
+// must be initialized for signing. The return code is 1 if the session was
+// initialized, 0 if the secret key or the derived nonce were invalid.
+func MusigSessionInitialize(
+	ctx *Context,
+	sessionID32 []byte,
+	msg32 []byte,
+	combinedPk *PublicKey,
+	pkHash32 []byte,
+	nSigners int,
+	myIndex int,
+	seckey []byte,
+) (int, *MusigSession, []byte, error) {
+	if len(sessionID32) != LenSessionID {
+		return 0, nil, nil, newError("MusigSessionInitialize", 0, ErrorSessionIDSize)
+	}
+	if len(msg32) != LenMessageHash {
+		return 0, nil, nil, newError("MusigSessionInitialize", 0, ErrorMessageHashSize)
+	}
+	if combinedPk == nil {
+		return 0, nil, nil, newError("MusigSessionInitialize", 0, ErrorPublicKeyNil)
+	}
+	if len(pkHash32) != LenPublicKeyHash {
+		return 0, nil, nil, newError("MusigSessionInitialize", 0, ErrorPublicKeyHashSize)
+	}
+	if nSigners < 1 {
+		return 0, nil, nil, newError("MusigSessionInitialize", 0, ErrorPublicKeyCount)
+	}
+	if myIndex < 0 || myIndex >= nSigners {
+		return 0, nil, nil, newError("MusigSessionInitialize", 0, ErrorMusigSignerIndex)
+	}
+	if len(seckey) != LenPrivateKey {
+		return 0, nil, nil, newError("MusigSessionInitialize", 0, ErrorPrivateKeySize)
+	}
+
+	if err := ctx.require(ContextSign); err != nil {
+		return 0, nil, nil, newError("MusigSessionInitialize", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	session := newMusigSession(nSigners)
+	commitment := make([]byte, LenNonceCommitment)
+	result, err := call("secp256k1_musig_session_initialize", func() C.int {
+		return C.secp256k1_musig_session_initialize(
+			ctx.ctx,
+			session.session,
+			&session.signers[0],
+			cBuf(commitment),
+			cBuf(sessionID32),
+			cBuf(msg32),
+			combinedPk.pk,
+			cBuf(pkHash32),
+			C.size_t(nSigners),
+			C.size_t(myIndex),
+			cBuf(seckey))
+	})
+	if err != nil {
+		session.clear()
+		return 0, nil, nil, newError("MusigSessionInitialize", 0, err)
+	}
+	if result != 1 {
+		session.clear()
+		return result, nil, nil, newError("MusigSessionInitialize", result, ErrorMusigSessionInitialize)
+	}
+	session.round = MusigRoundCommitment
+	return result, session, commitment, nil
+}
+
+// MusigSessionInitializeVerifier initializes a session that verifies the
+// nonces and the partial signatures of the signers without taking part in
+// the signature, given the nonce commitments of all the signers. The
+// returned session is in the MusigRoundNonce round. The return code is 1 if
+// the session was initialized, 0 otherwise.
+func MusigSessionInitializeVerifier(
+	ctx *Context,
+	msg32 []byte,
+	combinedPk *PublicKey,
+	pkHash32 []byte,
+	commitments [][]byte,
+) (int, *MusigSession, error) {
+	if len(msg32) != LenMessageHash {
+		return 0, nil, newError("MusigSessionInitializeVerifier", 0, ErrorMessageHashSize)
+	}
+	if combinedPk == nil {
+		return 0, nil, newError("MusigSessionInitializeVerifier", 0, ErrorPublicKeyNil)
+	}
+	if len(pkHash32) != LenPublicKeyHash {
+		return 0, nil, newError("MusigSessionInitializeVerifier", 0, ErrorPublicKeyHashSize)
+	}
+	n := len(commitments)
+	if n < 1 {
+		return 0, nil, newError("MusigSessionInitializeVerifier", 0, ErrorPublicKeyCount)
+	}
+	buf, err := concatNonceCommitments("MusigSessionInitializeVerifier", commitments)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("MusigSessionInitializeVerifier", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	array := C.makeNonceCommitmentsArray(cBuf(buf), C.int(n))
+	defer C.freeNonceCommitmentsArray(array)
+
+	session := newMusigSession(n)
+	result, err := call("secp256k1_musig_session_initialize_verifier", func() C.int {
+		return C.secp256k1_musig_session_initialize_verifier(
+			ctx.ctx,
+			session.session,
+			&session.signers[0],
+			cBuf(msg32),
+			combinedPk.pk,
+			cBuf(pkHash32),
+			array,
+			C.size_t(n))
+	})
+	runtime.KeepAlive(buf)
+	if err != nil {
+		return 0, nil, newError("MusigSessionInitializeVerifier", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("MusigSessionInitializeVerifier", result, ErrorMusigSessionInitialize)
+	}
+	session.round = MusigRoundNonce
+	return result, session, nil
+}
+
+func newMusigSession(nSigners int) *MusigSession {
+	return &MusigSession{
+		session: &C.secp256k1_musig_session{},
+		signers: make([]C.secp256k1_musig_session_signer_data, nSigners),
+	}
+}
+
+// concatNonceCommitments checks the size of the nonce commitments and
+// returns them concatenated, for makeNonceCommitmentsArray.
+func concatNonceCommitments(op string, commitments [][]byte) ([]byte, error) {
+	buf := make([]byte, 0, len(commitments)*LenNonceCommitment)
+	for i, commitment := range commitments {
+		if len(commitment) != LenNonceCommitment {
+			return nil, newIndexError(op, i, ErrorNonceCommitmentSize)
+		}
+		buf = append(buf, commitment...)
+	}
+	return buf, nil
+}
+
+// clear zeroes the secret key and the secret nonce of the session, which can
+// no longer sign.
+func (s *MusigSession) clear() {
+	for i := range s.session.seckey {
+		s.session.seckey[i] = 0
+		s.session.secnonce[i] = 0
+	}
+	s.session.has_secret_data = 0
+}
+
+// expect returns an error for op if the session is not in one of the given
+// rounds.
+func (s *MusigSession) expect(op string, rounds ...MusigRound) error {
+	for _, round := range rounds {
+		if s.round == round {
+			return nil
+		}
+	}
+	return newError(op, 0, ErrorMusigRound)
+}
+
+// Round returns the last round completed by the session
+func (s *MusigSession) Round() MusigRound {
+	return s.round
+}
+
+// Signers returns the number of signers of the session
+func (s *MusigSession) Signers() int {
+	return len(s.signers)
+}
+
+// PublicNonce returns the public nonce of the signer, given the nonce
+// commitments of all the signers, its own included, ordered by index. The
+// nonce must be sent to the other signers only after the commitments have
+// been received from all of them. It can be called again, with the same
+// commitments, to send the nonce again.
+func (s *MusigSession) PublicNonce(ctx *Context, commitments [][]byte) (int, *PublicKey, error) {
+	if err := s.expect("PublicNonce", MusigRoundCommitment, MusigRoundNonce); err != nil {
+		return 0, nil, err
+	}
+	if s.session.has_secret_data == 0 {
+		return 0, nil, newError("PublicNonce", 0, ErrorMusigVerifierSession)
+	}
+	n := len(commitments)
+	if n != len(s.signers) {
+		return 0, nil, newError("PublicNonce", 0, ErrorMusigSignerCount)
+	}
+	buf, err := concatNonceCommitments("PublicNonce", commitments)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("PublicNonce", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	array := C.makeNonceCommitmentsArray(cBuf(buf), C.int(n))
+	defer C.freeNonceCommitmentsArray(array)
+
+	// the library stores the commitments before comparing them with the ones
+	// received earlier, so they are restored if the comparison fails
+	signers := make([]C.secp256k1_musig_session_signer_data, n)
+	copy(signers, s.signers)
+
+	nonce := newPublicKey()
+	result, err := call("secp256k1_musig_session_get_public_nonce", func() C.int {
+		return C.secp256k1_musig_session_get_public_nonce(
+			ctx.ctx,
+			s.session,
+			&s.signers[0],
+			nonce.pk,
+			array,
+			C.size_t(n),
+			nil)
+	})
+	runtime.KeepAlive(buf)
+	if err != nil {
+		return 0, nil, newError("PublicNonce", 0, err)
+	}
+	if result != 1 {
+		copy(s.signers, signers)
+		return result, nil, newError("PublicNonce", result, ErrorMusigPublicNonce)
+	}
+	s.round = MusigRoundNonce
+	return result, nonce, nil
+}
+
+// SetNonce sets the public nonce of the signer at index, checking it against
+// the nonce commitment received from it. The nonces of all the signers, the
+// own one included, must be set before combining them. The return code is 1
+// if the nonce was set, 0 if it does not match the commitment.
+func (s *MusigSession) SetNonce(ctx *Context, index int, nonce *PublicKey) (int, error) {
+	if err := s.expect("SetNonce", MusigRoundNonce); err != nil {
+		return 0, err
+	}
+	if index < 0 || index >= len(s.signers) {
+		return 0, newError("SetNonce", 0, ErrorMusigSignerIndex)
+	}
+	if nonce == nil {
+		return 0, newError("SetNonce", 0, ErrorPublicKeyNil)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, newError("SetNonce", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_musig_set_nonce", func() C.int {
+		return C.secp256k1_musig_set_nonce(ctx.ctx, &s.signers[index], nonce.pk)
+	})
+	if err != nil {
+		return 0, newIndexError("SetNonce", index, err)
+	}
+	if result != 1 {
+		return result, newIndexError("SetNonce", index, ErrorMusigNonceCommitment)
+	}
+	return result, nil
+}
+
+// CombineNonces combines the nonces of all the signers, which must have been
+// set with SetNonce. The return code is 1 if the nonces were combined, 0 if
+// a nonce is missing.
+func (s *MusigSession) CombineNonces(ctx *Context) (int, error) {
+	return s.combineNonces("CombineNonces", ctx, nil)
+}
+
+// CombineNoncesWithAdaptor is like CombineNonces but also adds the public
+// adaptor, the point of a secret adaptor, to the combined nonce. The partial
+// signatures of such session combine into an adaptor signature, that becomes
+// a valid signature only once one of them is adapted with the secret adaptor
+// by MusigPartialSigAdapt. In turn, the secret adaptor can be extracted from
+// the valid signature by MusigExtractSecretAdaptor. All the signers must use
+// the same adaptor.
+func (s *MusigSession) CombineNoncesWithAdaptor(ctx *Context, adaptor *PublicKey) (int, error) {
+	if adaptor == nil {
+		return 0, newError("CombineNoncesWithAdaptor", 0, ErrorPublicKeyNil)
+	}
+	return s.combineNonces("CombineNoncesWithAdaptor", ctx, adaptor.pk)
+}
+
+func (s *MusigSession) combineNonces(op string, ctx *Context, adaptor *C.secp256k1_pubkey) (int, error) {
+	if err := s.expect(op, MusigRoundNonce); err != nil {
+		return 0, err
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, newError(op, 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_musig_session_combine_nonces", func() C.int {
+		return C.secp256k1_musig_session_combine_nonces(
+			ctx.ctx,
+			s.session,
+			&s.signers[0],
+			C.size_t(len(s.signers)),
+			nil,
+			adaptor)
+	})
+	if err != nil {
+		return 0, newError(op, 0, err)
+	}
+	if result != 1 {
+		return result, newError(op, result, ErrorMusigNonceCombine)
+	}
+	s.round = MusigRoundCombined
+	return result, nil
+}
+
+// NonceIsNegated reports whether the combined nonce had to be negated, which
+// must be given to MusigPartialSigAdapt and MusigExtractSecretAdaptor. It is
+// meaningful only once the nonces have been combined.
+func (s *MusigSession) NonceIsNegated() bool {
+	return s.session.nonce_is_negated != 0
+}
+
+// PartialSign creates the partial signature of the signer. It can be called
+// only once per session, after which the secret nonce is cleared so that it
+// can never be reused. The return code is 1 if the partial signature was
+// created, 0 otherwise.
+func (s *MusigSession) PartialSign(ctx *Context) (int, *MusigPartialSignature, error) {
+	if err := s.expect("PartialSign", MusigRoundCombined); err != nil {
+		return 0, nil, err
+	}
+	if s.session.has_secret_data == 0 {
+		return 0, nil, newError("PartialSign", 0, ErrorMusigVerifierSession)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("PartialSign", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newMusigPartialSignature()
+	result, err := call("secp256k1_musig_partial_sign", func() C.int {
+		return C.secp256k1_musig_partial_sign(ctx.ctx, s.session, sig.sig)
+	})
+	s.clear()
+	s.round = MusigRoundSigned
+	if err != nil {
+		return 0, nil, newError("PartialSign", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("PartialSign", result, ErrorMusigPartialSign)
+	}
+	return result, sig, nil
+}
+
+// PartialSigVerify verifies the partial signature of the signer at index
+// with its public key. The context must be initialized for verification.
+// The return code is 1 for a correct partial signature, 0 for an incorrect
+// one, in which case no error is returned.
+func (s *MusigSession) PartialSigVerify(ctx *Context, index int, sig *MusigPartialSignature, publicKey *PublicKey) (int, error) {
+	if err := s.expect("PartialSigVerify", MusigRoundCombined, MusigRoundSigned); err != nil {
+		return 0, err
+	}
+	if index < 0 || index >= len(s.signers) {
+		return 0, newError("PartialSigVerify", 0, ErrorMusigSignerIndex)
+	}
+	if sig == nil {
+		return 0, newError("PartialSigVerify", 0, ErrorMusigPartialSignatureNil)
+	}
+	if publicKey == nil {
+		return 0, newError("PartialSigVerify", 0, ErrorPublicKeyNil)
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, newError("PartialSigVerify", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_musig_partial_sig_verify", func() C.int {
+		return C.secp256k1_musig_partial_sig_verify(ctx.ctx, s.session, &s.signers[index], sig.sig, publicKey.pk)
+	})
+	if err != nil {
+		return 0, newIndexError("PartialSigVerify", index, err)
+	}
+	return result, nil
+}
+
+// PartialSigCombine combines the partial signatures of all the signers into
+// a Schnorr signature for the combined public key. If the combined public
+// key was tweaked with EcPubKeyTweakAdd before initializing the session, the
+// same 32-byte tweak must be given, otherwise it must be nil. The return
+// code is 1 if the partial signatures were combined, 0 if any of them or the
+// tweak was out of range; a return code of 1 does not mean that the
+// signature is valid. The signature follows the bip-schnorr draft, it is
+// verified with SchnorrVerifyLegacy.
+func (s *MusigSession) PartialSigCombine(ctx *Context, sigs []*MusigPartialSignature, tweak32 []byte) (int, *SchnorrSignature, error) {
+	if err := s.expect("PartialSigCombine", MusigRoundCombined, MusigRoundSigned); err != nil {
+		return 0, nil, err
+	}
+	n := len(sigs)
+	if n != len(s.signers) {
+		return 0, nil, newError("PartialSigCombine", 0, ErrorMusigSignerCount)
+	}
+	partials := make([]C.secp256k1_musig_partial_signature, n)
+	for i, sig := range sigs {
+		if sig == nil {
+			return 0, nil, newIndexError("PartialSigCombine", i, ErrorMusigPartialSignatureNil)
+		}
+		partials[i] = *sig.sig
+	}
+	if tweak32 != nil && len(tweak32) != LenPrivateKey {
+		return 0, nil, newError("PartialSigCombine", 0, ErrorTweakSize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("PartialSigCombine", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newSchnorrSignature()
+	result, err := call("secp256k1_musig_partial_sig_combine", func() C.int {
+		return C.secp256k1_musig_partial_sig_combine(ctx.ctx, s.session, sig.sig, &partials[0], C.size_t(n), cBuf(tweak32))
+	})
+	if err != nil {
+		return 0, nil, newError("PartialSigCombine", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("PartialSigCombine", result, ErrorMusigPartialSigCombine)
+	}
+	return result, sig, nil
+}
+
+// MusigPartialSigAdapt adapts a partial signature, created by a session whose
+// nonces were combined with the public adaptor of secAdaptor32, by adding the
+// secret adaptor to it. Combined with the partial signatures of the other
+// signers, it gives a valid signature. The return code is 1 if the partial
+// signature was adapted, 0 if it or the secret adaptor were out of range.
+func MusigPartialSigAdapt(
+	ctx *Context,
+	partialSig *MusigPartialSignature,
+	secAdaptor32 []byte,
+	nonceIsNegated bool,
+) (int, *MusigPartialSignature, error) {
+	if partialSig == nil {
+		return 0, nil, newError("MusigPartialSigAdapt", 0, ErrorMusigPartialSignatureNil)
+	}
+	if len(secAdaptor32) != LenPrivateKey {
+		return 0, nil, newError("MusigPartialSigAdapt", 0, ErrorPrivateKeySize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("MusigPartialSigAdapt", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	adaptorSig := newMusigPartialSignature()
+	result, err := call("secp256k1_musig_partial_sig_adapt", func() C.int {
+		return C.secp256k1_musig_partial_sig_adapt(ctx.ctx, adaptorSig.sig, partialSig.sig, cBuf(secAdaptor32), cBool(nonceIsNegated))
+	})
+	if err != nil {
+		return 0, nil, newError("MusigPartialSigAdapt", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("MusigPartialSigAdapt", result, ErrorMusigPartialSigAdapt)
+	}
+	return result, adaptorSig, nil
+}
+
+// MusigExtractSecretAdaptor extracts the secret adaptor from a valid
+// signature, published by a signer that adapted its partial signature, and
+// from the partial signatures of all the signers before adaptation. The
+// partial signatures must have been verified with PartialSigVerify, since
+// the result is meaningless otherwise. The return code is 1 if the secret
+// adaptor was extracted, 0 if any signature was out of range.
+func MusigExtractSecretAdaptor(
+	ctx *Context,
+	sig *SchnorrSignature,
+	partialSigs []*MusigPartialSignature,
+	nonceIsNegated bool,
+) (int, []byte, error) {
+	if sig == nil {
+		return 0, nil, newError("MusigExtractSecretAdaptor", 0, ErrorSchnorrSignatureNil)
+	}
+	n := len(partialSigs)
+	if n < 1 {
+		return 0, nil, newError("MusigExtractSecretAdaptor", 0, ErrorMusigSignerCount)
+	}
+	partials := make([]C.secp256k1_musig_partial_signature, n)
+	for i, partialSig := range partialSigs {
+		if partialSig == nil {
+			return 0, nil, newIndexError("MusigExtractSecretAdaptor", i, ErrorMusigPartialSignatureNil)
+		}
+		partials[i] = *partialSig.sig
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("MusigExtractSecretAdaptor", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	secAdaptor := make([]byte, LenPrivateKey)
+	result, err := call("secp256k1_musig_extract_secret_adaptor", func() C.int {
+		return C.secp256k1_musig_extract_secret_adaptor(ctx.ctx, cBuf(secAdaptor), sig.sig, &partials[0], C.size_t(n), cBool(nonceIsNegated))
+	})
+	if err != nil {
+		return 0, nil, newError("MusigExtractSecretAdaptor", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("MusigExtractSecretAdaptor", result, ErrorMusigExtractSecretAdaptor)
+	}
+	return result, secAdaptor, nil
+}
+
+func cBool(b bool) C.int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// MusigPartialSignatureParse parses a 32-byte MuSig partial signature. The
+// return code is always 1, since a partial signature out of range can only be
+// detected when verifying or combining it.
+func MusigPartialSignatureParse(ctx *Context, input32 []byte) (int, *MusigPartialSignature, error) {
+	if len(input32) != LenMusigPartialSignature {
+		return 0, nil, newError("MusigPartialSignatureParse", 0, ErrorMusigPartialSignatureSize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("MusigPartialSignatureParse", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newMusigPartialSignature()
+	result, err := call("secp256k1_musig_partial_signature_parse", func() C.int {
+		return C.secp256k1_musig_partial_signature_parse(ctx.ctx, sig.sig, cBuf(input32))
+	})
+	if err != nil {
+		return 0, nil, newError("MusigPartialSignatureParse", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("MusigPartialSignatureParse", result, ErrorMusigPartialSignatureParse)
+	}
+	return result, sig, nil
+}
+
+// MusigPartialSignatureSerialize serializes a MuSig partial signature into
+// 32 bytes. The return code is always 1.
+func MusigPartialSignatureSerialize(ctx *Context, sig *MusigPartialSignature) (int, []byte, error) {
+	if sig == nil {
+		return 0, nil, newError("MusigPartialSignatureSerialize", 0, ErrorMusigPartialSignatureNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("MusigPartialSignatureSerialize", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	output := make([]byte, LenMusigPartialSignature)
+	result, err := call("secp256k1_musig_partial_signature_serialize", func() C.int {
+		return C.secp256k1_musig_partial_signature_serialize(ctx.ctx, cBuf(output), sig.sig)
+	})
+	if err != nil {
+		return 0, nil, newError("MusigPartialSignatureSerialize", 0, err)
+	}
+	return result, output, nil
+}
+
+// Serialize returns the partial signature serialized into 32 bytes
+func (sig *MusigPartialSignature) Serialize() []byte {
+	_, bytes, _ := MusigPartialSignatureSerialize(SharedContext(ContextNone), sig)
+	return bytes
+}
@@ -0,0 +1,390 @@
+package secp256k1
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type musigTestSigner struct {
+	seckey     []byte
+	pk         *PublicKey
+	session    *MusigSession
+	commitment []byte
+	nonce      *PublicKey
+}
+
+// newMusigTestSigners creates n signers, combines their public keys and
+// initializes a signing session for each of them, returning the combined
+// public key and the public key hash.
+func newMusigTestSigners(t *testing.T, ctx *Context, n int, msg []byte) ([]*musigTestSigner, *PublicKey, []byte) {
+	signers := make([]*musigTestSigner, n)
+	pks := make([]*PublicKey, n)
+	for i := range signers {
+		seckey := make([]byte, 32)
+		rand.Read(seckey)
+		_, pk, err := EcPubkeyCreate(ctx, seckey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signers[i] = &musigTestSigner{seckey: seckey, pk: pk}
+		pks[i] = pk
+	}
+
+	_, combined, pkHash, err := MusigPubkeyCombine(ctx, nil, pks)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, signer := range signers {
+		sessionID := make([]byte, 32)
+		rand.Read(sessionID)
+		_, session, commitment, err := MusigSessionInitialize(ctx, sessionID, msg, combined, pkHash, n, i, signer.seckey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, MusigRoundCommitment, session.Round())
+		signer.session = session
+		signer.commitment = commitment
+	}
+	return signers, combined, pkHash
+}
+
+func TestMusigSession(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	const n = 3
+	msg := make([]byte, 32)
+	rand.Read(msg)
+	signers, combined, pkHash := newMusigTestSigners(t, ctx, n, msg)
+
+	// the combined public key does not depend on the scratch space
+	scratch, _ := ScratchSpaceCreate(ctx, 1024*1024)
+	defer ScratchSpaceDestroy(scratch)
+	pks := make([]*PublicKey, n)
+	for i, signer := range signers {
+		pks[i] = signer.pk
+	}
+	_, other, otherHash, err := MusigPubkeyCombine(ctx, scratch, pks)
+	assert.NoError(t, err)
+	assert.Equal(t, *combined.pk, *other.pk)
+	assert.Equal(t, pkHash, otherHash)
+
+	// round 1: exchange the nonce commitments
+	commitments := make([][]byte, n)
+	for i, signer := range signers {
+		commitments[i] = signer.commitment
+	}
+
+	// round 2: exchange the nonces
+	for _, signer := range signers {
+		_, nonce, err := signer.session.PublicNonce(ctx, commitments)
+		assert.NoError(t, err)
+		signer.nonce = nonce
+		assert.Equal(t, MusigRoundNonce, signer.session.Round())
+	}
+	_, verifier, err := MusigSessionInitializeVerifier(ctx, msg, combined, pkHash, commitments)
+	assert.NoError(t, err)
+	assert.Equal(t, n, verifier.Signers())
+	for _, session := range append([]*MusigSession{verifier}, sessionsOf(signers)...) {
+		for i, signer := range signers {
+			_, err := session.SetNonce(ctx, i, signer.nonce)
+			assert.NoError(t, err)
+		}
+		_, err := session.CombineNonces(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, MusigRoundCombined, session.Round())
+	}
+
+	// round 3: exchange the partial signatures
+	partials := make([]*MusigPartialSignature, n)
+	for i, signer := range signers {
+		_, partial, err := signer.session.PartialSign(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, MusigRoundSigned, signer.session.Round())
+
+		// serialized for the transport
+		_, parsed, err := MusigPartialSignatureParse(ctx, partial.Serialize())
+		assert.NoError(t, err)
+		assert.Equal(t, partial.Serialize(), parsed.Serialize())
+		partials[i] = parsed
+	}
+	for i, signer := range signers {
+		for _, session := range []*MusigSession{verifier, signers[0].session} {
+			result, err := session.PartialSigVerify(ctx, i, partials[i], signer.pk)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, result)
+			result, err = session.PartialSigVerify(ctx, (i+1)%n, partials[i], signer.pk)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, result)
+		}
+	}
+
+	_, sig, err := signers[1].session.PartialSigCombine(ctx, partials, nil)
+	assert.NoError(t, err)
+	result, err := SchnorrVerifyLegacy(ctx, sig, msg, combined)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+
+	_, verifierSig, err := verifier.PartialSigCombine(ctx, partials, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, sig.Serialize(), verifierSig.Serialize())
+}
+
+func TestMusigSessionTweaked(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	const n = 2
+	msg := make([]byte, 32)
+	rand.Read(msg)
+	tweak := make([]byte, 32)
+	rand.Read(tweak)
+
+	pks := make([]*PublicKey, n)
+	seckeys := make([][]byte, n)
+	for i := range pks {
+		seckeys[i] = make([]byte, 32)
+		rand.Read(seckeys[i])
+		_, pks[i], _ = EcPubkeyCreate(ctx, seckeys[i])
+	}
+	_, combined, pkHash, _ := MusigPubkeyCombine(ctx, nil, pks)
+	_, err := EcPubKeyTweakAdd(ctx, combined, tweak)
+	assert.NoError(t, err)
+
+	sessions := make([]*MusigSession, n)
+	commitments := make([][]byte, n)
+	for i := range sessions {
+		sessionID := make([]byte, 32)
+		rand.Read(sessionID)
+		_, sessions[i], commitments[i], err = MusigSessionInitialize(ctx, sessionID, msg, combined, pkHash, n, i, seckeys[i])
+		assert.NoError(t, err)
+	}
+	nonces := make([]*PublicKey, n)
+	for i, session := range sessions {
+		_, nonces[i], _ = session.PublicNonce(ctx, commitments)
+	}
+	partials := make([]*MusigPartialSignature, n)
+	for i, session := range sessions {
+		for j, nonce := range nonces {
+			session.SetNonce(ctx, j, nonce)
+		}
+		_, err := session.CombineNonces(ctx)
+		assert.NoError(t, err)
+		_, partials[i], err = session.PartialSign(ctx)
+		assert.NoError(t, err)
+	}
+
+	_, sig, err := sessions[0].PartialSigCombine(ctx, partials, tweak)
+	assert.NoError(t, err)
+	result, _ := SchnorrVerifyLegacy(ctx, sig, msg, combined)
+	assert.Equal(t, 1, result)
+}
+
+func TestMusigSessionRounds(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	const n = 2
+	msg := make([]byte, 32)
+	signers, combined, pkHash := newMusigTestSigners(t, ctx, n, msg)
+	session := signers[0].session
+
+	// nothing can happen before the commitments are exchanged
+	_, err := session.SetNonce(ctx, 0, signers[0].pk)
+	assert.True(t, errors.Is(err, ErrorMusigRound))
+	_, err = session.CombineNonces(ctx)
+	assert.True(t, errors.Is(err, ErrorMusigRound))
+	_, _, err = session.PartialSign(ctx)
+	assert.True(t, errors.Is(err, ErrorMusigRound))
+
+	commitments := [][]byte{signers[0].commitment, signers[1].commitment}
+	_, _, err = session.PublicNonce(ctx, commitments[:1])
+	assert.True(t, errors.Is(err, ErrorMusigSignerCount))
+	_, nonce0, err := session.PublicNonce(ctx, commitments)
+	assert.NoError(t, err)
+	_, nonce1, _ := signers[1].session.PublicNonce(ctx, commitments)
+
+	// the commitments cannot change once the nonce has been revealed
+	_, _, err = session.PublicNonce(ctx, [][]byte{commitments[0], make([]byte, 32)})
+	assert.True(t, errors.Is(err, ErrorMusigPublicNonce))
+
+	// a nonce not matching its commitment is rejected, and the offending
+	// signer is reported
+	_, err = session.SetNonce(ctx, 1, nonce0)
+	assert.True(t, errors.Is(err, ErrorMusigNonceCommitment))
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 1, e.Index)
+	_, err = session.SetNonce(ctx, 2, nonce1)
+	assert.True(t, errors.Is(err, ErrorMusigSignerIndex))
+
+	// nonces are combined only when all of them are set
+	_, err = session.SetNonce(ctx, 1, nonce1)
+	assert.NoError(t, err)
+	_, err = session.CombineNonces(ctx)
+	assert.True(t, errors.Is(err, ErrorMusigNonceCombine))
+	_, err = session.SetNonce(ctx, 0, nonce0)
+	assert.NoError(t, err)
+	_, err = session.CombineNonces(ctx)
+	assert.NoError(t, err)
+	_, err = session.SetNonce(ctx, 0, nonce0)
+	assert.True(t, errors.Is(err, ErrorMusigRound))
+
+	// a session signs only once
+	_, _, err = session.PartialSign(ctx)
+	assert.NoError(t, err)
+	_, _, err = session.PartialSign(ctx)
+	assert.True(t, errors.Is(err, ErrorMusigRound))
+
+	// a verifier session cannot sign
+	_, verifier, _ := MusigSessionInitializeVerifier(ctx, msg, combined, pkHash, commitments)
+	_, _, err = verifier.PublicNonce(ctx, commitments)
+	assert.True(t, errors.Is(err, ErrorMusigVerifierSession))
+	verifier.SetNonce(ctx, 0, nonce0)
+	verifier.SetNonce(ctx, 1, nonce1)
+	verifier.CombineNonces(ctx)
+	_, _, err = verifier.PartialSign(ctx)
+	assert.True(t, errors.Is(err, ErrorMusigVerifierSession))
+}
+
+func TestMusigInvalidInput(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	_, _, _, err := MusigPubkeyCombine(ctx, nil, nil)
+	assert.True(t, errors.Is(err, ErrorPublicKeyCount))
+
+	seckey := make([]byte, 32)
+	seckey[31] = 1
+	_, pk, _ := EcPubkeyCreate(ctx, seckey)
+	_, _, _, err = MusigPubkeyCombine(ctx, nil, []*PublicKey{pk, nil})
+	assert.True(t, errors.Is(err, ErrorPublicKeyNil))
+
+	_, combined, pkHash, _ := MusigPubkeyCombine(ctx, nil, []*PublicKey{pk})
+	id := make([]byte, 32)
+	msg := make([]byte, 32)
+	_, _, _, err = MusigSessionInitialize(ctx, id[:31], msg, combined, pkHash, 1, 0, seckey)
+	assert.True(t, errors.Is(err, ErrorSessionIDSize))
+	_, _, _, err = MusigSessionInitialize(ctx, id, msg, combined, pkHash, 1, 1, seckey)
+	assert.True(t, errors.Is(err, ErrorMusigSignerIndex))
+	overflow := make([]byte, 32)
+	for i := range overflow {
+		overflow[i] = 0xff
+	}
+	_, _, _, err = MusigSessionInitialize(ctx, id, msg, combined, pkHash, 1, 0, overflow)
+	assert.True(t, errors.Is(err, ErrorMusigSessionInitialize))
+
+	verifyCtx, _ := ContextCreate(ContextVerify)
+	defer ContextDestroy(verifyCtx)
+	_, _, _, err = MusigSessionInitialize(verifyCtx, id, msg, combined, pkHash, 1, 0, seckey)
+	assert.True(t, errors.Is(err, ErrContextCapability))
+
+	_, _, err = MusigPartialSignatureParse(ctx, make([]byte, 33))
+	assert.True(t, errors.Is(err, ErrorMusigPartialSignatureSize))
+
+	// a partial signature out of range is detected only when combined
+	_, partial, err := MusigPartialSignatureParse(ctx, overflow)
+	assert.NoError(t, err)
+	_, session, commitment, _ := MusigSessionInitialize(ctx, id, msg, combined, pkHash, 1, 0, seckey)
+	_, nonce, _ := session.PublicNonce(ctx, [][]byte{commitment})
+	session.SetNonce(ctx, 0, nonce)
+	session.CombineNonces(ctx)
+	_, _, err = session.PartialSigCombine(ctx, []*MusigPartialSignature{partial}, nil)
+	assert.True(t, errors.Is(err, ErrorMusigPartialSigCombine))
+}
+
+func sessionsOf(signers []*musigTestSigner) []*MusigSession {
+	sessions := make([]*MusigSession, len(signers))
+	for i, signer := range signers {
+		sessions[i] = signer.session
+	}
+	return sessions
+}
+
+func TestMusigAdaptorSignature(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	const n = 2
+	// the combined nonce is negated about half of the times
+	for k := 0; k < 8; k++ {
+		msg := make([]byte, 32)
+		rand.Read(msg)
+		signers, combined, _ := newMusigTestSigners(t, ctx, n, msg)
+
+		secAdaptor := make([]byte, 32)
+		rand.Read(secAdaptor)
+		_, adaptor, _ := EcPubkeyCreate(ctx, secAdaptor)
+
+		commitments := [][]byte{signers[0].commitment, signers[1].commitment}
+		for _, signer := range signers {
+			_, signer.nonce, _ = signer.session.PublicNonce(ctx, commitments)
+		}
+		partials := make([]*MusigPartialSignature, n)
+		for i, signer := range signers {
+			for j, other := range signers {
+				signer.session.SetNonce(ctx, j, other.nonce)
+			}
+			_, err := signer.session.CombineNoncesWithAdaptor(ctx, adaptor)
+			assert.NoError(t, err)
+			_, partials[i], err = signer.session.PartialSign(ctx)
+			assert.NoError(t, err)
+		}
+		session := signers[0].session
+		nonceIsNegated := session.NonceIsNegated()
+		assert.Equal(t, nonceIsNegated, signers[1].session.NonceIsNegated())
+
+		// the partial signatures verify, but do not combine into a valid
+		// signature without the secret adaptor
+		for i, signer := range signers {
+			result, err := session.PartialSigVerify(ctx, i, partials[i], signer.pk)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, result)
+		}
+		_, sig, err := session.PartialSigCombine(ctx, partials, nil)
+		assert.NoError(t, err)
+		result, _ := SchnorrVerifyLegacy(ctx, sig, msg, combined)
+		assert.Equal(t, 0, result)
+
+		// the owner of the secret adaptor completes the signature
+		_, adapted, err := MusigPartialSigAdapt(ctx, partials[1], secAdaptor, nonceIsNegated)
+		assert.NoError(t, err)
+		_, sig, err = session.PartialSigCombine(ctx, []*MusigPartialSignature{partials[0], adapted}, nil)
+		assert.NoError(t, err)
+		result, _ = SchnorrVerifyLegacy(ctx, sig, msg, combined)
+		assert.Equal(t, 1, result)
+
+		// which reveals the secret adaptor to the other signers
+		_, extracted, err := MusigExtractSecretAdaptor(ctx, sig, partials, nonceIsNegated)
+		assert.NoError(t, err)
+		assert.Equal(t, secAdaptor, extracted)
+	}
+}
+
+func TestMusigAdaptorInvalidInput(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	_, err := (&MusigSession{round: MusigRoundNonce}).CombineNoncesWithAdaptor(ctx, nil)
+	assert.True(t, errors.Is(err, ErrorPublicKeyNil))
+
+	_, partial, _ := MusigPartialSignatureParse(ctx, make([]byte, 32))
+	_, _, err = MusigPartialSigAdapt(ctx, partial, make([]byte, 31), false)
+	assert.True(t, errors.Is(err, ErrorPrivateKeySize))
+	overflow := make([]byte, 32)
+	for i := range overflow {
+		overflow[i] = 0xff
+	}
+	_, _, err = MusigPartialSigAdapt(ctx, partial, overflow, false)
+	assert.True(t, errors.Is(err, ErrorMusigPartialSigAdapt))
+
+	_, sig, _ := SchnorrSignatureParse(ctx, make([]byte, 64))
+	_, _, err = MusigExtractSecretAdaptor(ctx, sig, nil, false)
+	assert.True(t, errors.Is(err, ErrorMusigSignerCount))
+	_, _, err = MusigExtractSecretAdaptor(ctx, sig, []*MusigPartialSignature{partial, nil}, false)
+	assert.True(t, errors.Is(err, ErrorMusigPartialSignatureNil))
+}
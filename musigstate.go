@@ -0,0 +1,274 @@
+package secp256k1
+
+/*
+#include "include/secp256k1.h"
+#include "include/secp256k1_schnorrsig.h"
+#include "include/secp256k1_musig.h"
+#cgo CFLAGS: -I${SRCDIR}/secp256k1-zkp -I${SRCDIR}/secp256k1-zkp/src
+*/
+import "C"
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const (
+	// MusigSessionVersion is the version of the format produced by
+	// MusigSessionExport
+	MusigSessionVersion byte = 1
+)
+
+// flags of an exported session
+const (
+	musigFlagNonceSet = 1 << iota
+	musigFlagNonceNegated
+	musigFlagCommitmentsHashSet
+	musigFlagMessageSet
+	musigFlagOwnNonce
+
+	musigFlagsAll = 1<<iota - 1
+)
+
+var (
+	ErrorMusigSessionSecret   = errors.New("musig session holds a secret nonce and cannot be exported")
+	ErrorMusigSessionVersion  = errors.New("unsupported musig session version")
+	ErrorMusigSessionEncoding = errors.New("invalid musig session encoding")
+)
+
+// MusigSessionExport serializes the public state of a session into a
+// versioned byte format, that MusigSessionImport restores. The export holds
+// the combined public key, the public key hash and the message, the nonce
+// commitments and the nonces of all the signers and the last completed round.
+//
+// Only verifier sessions, in any round, and signer sessions that reached
+// MusigRoundSigned can be exported: a verifier can be restored between two
+// rounds, but a signer cannot. The export of a session holding a secret
+// nonce, that is of a signer that has not created its partial signature
+// yet, is refused and no secret is ever part of the format, since a
+// restored session could otherwise sign twice with the same nonce, which
+// LEAKS THE SECRET KEY. A signer that restarts before signing must instead
+// abort the session and start a new one with a fresh session ID, and fresh
+// nonces, along with the other signers.
+func MusigSessionExport(ctx *Context, session *MusigSession) ([]byte, error) {
+	if session.session.has_secret_data != 0 {
+		return nil, newError("MusigSessionExport", 0, ErrorMusigSessionSecret)
+	}
+	if err := ctx.check(); err != nil {
+		return nil, newError("MusigSessionExport", 0, err)
+	}
+
+	s := session.session
+	var flags byte
+	if s.nonce_is_set != 0 {
+		flags |= musigFlagNonceSet
+	}
+	if s.nonce_is_negated != 0 {
+		flags |= musigFlagNonceNegated
+	}
+	if s.nonce_commitments_hash_is_set != 0 {
+		flags |= musigFlagCommitmentsHashSet
+	}
+	if s.msg_is_set != 0 {
+		flags |= musigFlagMessageSet
+	}
+	// the public nonce of a signer outlives its secret nonce
+	ownNonce := s.nonce != C.secp256k1_pubkey{}
+	if ownNonce {
+		flags |= musigFlagOwnNonce
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(MusigSessionVersion)
+	buf.WriteByte(byte(session.round))
+	buf.WriteByte(flags)
+	binary.Write(&buf, binary.BigEndian, uint32(s.n_signers))
+	if err := writeMusigPubkey(ctx, &buf, &s.combined_pk, true); err != nil {
+		return nil, err
+	}
+	buf.Write(goBytes32(&s.pk_hash))
+	buf.Write(goBytes32(&s.msg))
+	buf.Write(goBytes32(&s.nonce_commitments_hash))
+	if err := writeMusigPubkey(ctx, &buf, &s.combined_nonce, s.nonce_is_set != 0); err != nil {
+		return nil, err
+	}
+	if err := writeMusigPubkey(ctx, &buf, &s.nonce, ownNonce); err != nil {
+		return nil, err
+	}
+	for i := range session.signers {
+		signer := &session.signers[i]
+		buf.WriteByte(byte(signer.present))
+		binary.Write(&buf, binary.BigEndian, uint32(signer.index))
+		buf.Write(goBytes32(&signer.nonce_commitment))
+		if err := writeMusigPubkey(ctx, &buf, &signer.nonce, signer.present != 0); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// MusigSessionImport restores a session exported by MusigSessionExport. The
+// restored session holds no secret and cannot sign. An export whose round
+// does not agree with the state of the nonces is rejected.
+func MusigSessionImport(ctx *Context, data []byte) (*MusigSession, error) {
+	if err := ctx.check(); err != nil {
+		return nil, newError("MusigSessionImport", 0, err)
+	}
+	if len(data) < 1 {
+		return nil, newError("MusigSessionImport", 0, ErrorMusigSessionEncoding)
+	}
+	if data[0] != MusigSessionVersion {
+		return nil, newError("MusigSessionImport", 0, ErrorMusigSessionVersion)
+	}
+
+	r := bytes.NewReader(data[1:])
+	var header struct {
+		Round    byte
+		Flags    byte
+		NSigners uint32
+	}
+	if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+		return nil, newError("MusigSessionImport", 0, ErrorMusigSessionEncoding)
+	}
+	round := MusigRound(header.Round)
+	if !musigRoundAgrees(round, header.Flags) {
+		return nil, newError("MusigSessionImport", 0, ErrorMusigSessionEncoding)
+	}
+	// every signer takes at least 70 bytes, which bounds the allocation
+	if header.NSigners < 1 || uint64(header.NSigners)*70 > uint64(len(data)) {
+		return nil, newError("MusigSessionImport", 0, ErrorMusigSessionEncoding)
+	}
+
+	session := newMusigSession(int(header.NSigners))
+	session.round = round
+	s := session.session
+	s.n_signers = C.uint32_t(header.NSigners)
+	s.nonce_is_set = cFlag(header.Flags, musigFlagNonceSet)
+	s.nonce_is_negated = cFlag(header.Flags, musigFlagNonceNegated)
+	s.nonce_commitments_hash_is_set = cFlag(header.Flags, musigFlagCommitmentsHashSet)
+	s.msg_is_set = cFlag(header.Flags, musigFlagMessageSet)
+
+	ok := readMusigPubkey(ctx, r, &s.combined_pk, true) &&
+		readBytes32(r, &s.pk_hash) &&
+		readBytes32(r, &s.msg) &&
+		readBytes32(r, &s.nonce_commitments_hash) &&
+		readMusigPubkey(ctx, r, &s.combined_nonce, s.nonce_is_set != 0) &&
+		readMusigPubkey(ctx, r, &s.nonce, header.Flags&musigFlagOwnNonce != 0)
+	for i := range session.signers {
+		if !ok {
+			break
+		}
+		signer := &session.signers[i]
+		var present byte
+		var index uint32
+		ok = binary.Read(r, binary.BigEndian, &present) == nil &&
+			binary.Read(r, binary.BigEndian, &index) == nil &&
+			present <= 1 &&
+			readBytes32(r, &signer.nonce_commitment) &&
+			readMusigPubkey(ctx, r, &signer.nonce, present == 1)
+		signer.present = C.int(present)
+		signer.index = C.uint32_t(index)
+		// the nonces of all the signers are needed to combine them
+		ok = ok && (round < MusigRoundCombined || present == 1)
+	}
+	if !ok || r.Len() != 0 {
+		return nil, newError("MusigSessionImport", 0, ErrorMusigSessionEncoding)
+	}
+	return session, nil
+}
+
+// Export serializes the session with MusigSessionExport
+func (s *MusigSession) Export() ([]byte, error) {
+	return MusigSessionExport(SharedContext(ContextNone), s)
+}
+
+// musigRoundAgrees reports whether the flags of an exported session describe
+// a session without secrets in the given round: a verifier session from
+// MusigRoundNonce on, or a signer session in MusigRoundSigned.
+func musigRoundAgrees(round MusigRound, flags byte) bool {
+	if round < MusigRoundNonce || round > MusigRoundSigned {
+		return false
+	}
+	if flags&^musigFlagsAll != 0 || flags&musigFlagMessageSet == 0 {
+		return false
+	}
+	nonceSet := flags&musigFlagNonceSet != 0
+	if nonceSet != (round >= MusigRoundCombined) {
+		return false
+	}
+	if !nonceSet && flags&musigFlagNonceNegated != 0 {
+		return false
+	}
+	// only a signer has its own nonce and the hash of the commitments, and
+	// it holds a secret nonce until it signs
+	signer := flags&musigFlagOwnNonce != 0
+	if signer != (flags&musigFlagCommitmentsHashSet != 0) {
+		return false
+	}
+	return signer == (round == MusigRoundSigned)
+}
+
+// writeMusigPubkey writes the compressed serialization of pk if set is true,
+// 33 zero bytes otherwise.
+func writeMusigPubkey(ctx *Context, buf *bytes.Buffer, pk *C.secp256k1_pubkey, set bool) error {
+	if !set {
+		buf.Write(make([]byte, LenCompressed))
+		return nil
+	}
+	_, compressed, err := EcPubkeySerialize(ctx, &PublicKey{pk}, EcCompressed)
+	if err != nil {
+		return err
+	}
+	buf.Write(compressed)
+	return nil
+}
+
+// readMusigPubkey reads a public key written by writeMusigPubkey and parses
+// it into pk if set is true, otherwise it checks that it is all zeros.
+func readMusigPubkey(ctx *Context, r io.Reader, pk *C.secp256k1_pubkey, set bool) bool {
+	compressed := make([]byte, LenCompressed)
+	if _, err := io.ReadFull(r, compressed); err != nil {
+		return false
+	}
+	if !set {
+		return bytes.Equal(compressed, make([]byte, LenCompressed))
+	}
+	_, parsed, err := EcPubkeyParse(ctx, compressed)
+	if err != nil {
+		return false
+	}
+	*pk = *parsed.pk
+	return true
+}
+
+func readBytes32(r io.Reader, dst *[32]C.uchar) bool {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return false
+	}
+	cBytes32(dst, b)
+	return true
+}
+
+func goBytes32(src *[32]C.uchar) []byte {
+	out := make([]byte, 32)
+	for i := range src {
+		out[i] = byte(src[i])
+	}
+	return out
+}
+
+func cBytes32(dst *[32]C.uchar, src []byte) {
+	for i := range dst {
+		dst[i] = C.uchar(src[i])
+	}
+}
+
+func cFlag(flags byte, flag byte) C.int {
+	if flags&flag != 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,206 @@
+package secp256k1
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// offsets in an exported session: the round, the flags, and the first
+// signer after the version, round, flags, number of signers, combined public
+// key, public key hash, message, commitments hash, combined nonce and own
+// nonce. Each signer then takes 70 bytes.
+const (
+	musigOffsetRound  = 1
+	musigOffsetFlags  = 2
+	musigOffsetSigner = 1 + 1 + 1 + 4 + 33 + 32 + 32 + 32 + 33 + 33
+)
+
+// restore exports the session and imports it back, as a verifier that
+// restarts between two rounds or a signer that restarts once done
+func restore(t *testing.T, ctx *Context, session *MusigSession) *MusigSession {
+	data, err := MusigSessionExport(ctx, session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := MusigSessionImport(ctx, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, session.Round(), restored.Round())
+	assert.Equal(t, *session.session, *restored.session)
+	assert.Equal(t, session.signers, restored.signers)
+	return restored
+}
+
+func TestMusigSessionExportImport(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	const n = 3
+	msg := make([]byte, 32)
+	rand.Read(msg)
+	signers, combined, pkHash := newMusigTestSigners(t, ctx, n, msg)
+
+	commitments := make([][]byte, n)
+	for i, signer := range signers {
+		commitments[i] = signer.commitment
+	}
+	_, verifier, _ := MusigSessionInitializeVerifier(ctx, msg, combined, pkHash, commitments)
+	verifier = restore(t, ctx, verifier)
+
+	for i, signer := range signers {
+		_, signer.nonce, _ = signer.session.PublicNonce(ctx, commitments)
+		_, err := verifier.SetNonce(ctx, i, signer.nonce)
+		assert.NoError(t, err)
+		verifier = restore(t, ctx, verifier)
+	}
+	_, err := verifier.CombineNonces(ctx)
+	assert.NoError(t, err)
+	verifier = restore(t, ctx, verifier)
+
+	partials := make([]*MusigPartialSignature, n)
+	for i, signer := range signers {
+		for j, other := range signers {
+			_, err := signer.session.SetNonce(ctx, j, other.nonce)
+			assert.NoError(t, err)
+		}
+		_, err := signer.session.CombineNonces(ctx)
+		assert.NoError(t, err)
+		_, partials[i], err = signer.session.PartialSign(ctx)
+		assert.NoError(t, err)
+
+		// no secret is left once signed
+		signer.session = restore(t, ctx, signer.session)
+		assert.Equal(t, MusigRoundSigned, signer.session.Round())
+		_, _, err = signer.session.PartialSign(ctx)
+		assert.True(t, errors.Is(err, ErrorMusigRound))
+	}
+
+	for i, signer := range signers {
+		result, err := verifier.PartialSigVerify(ctx, i, partials[i], signer.pk)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+	}
+	_, sig, err := signers[2].session.PartialSigCombine(ctx, partials, nil)
+	assert.NoError(t, err)
+	result, _ := SchnorrVerifyLegacy(ctx, sig, msg, combined)
+	assert.Equal(t, 1, result)
+}
+
+func TestMusigSessionExportSecret(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	msg := make([]byte, 32)
+	signers, _, _ := newMusigTestSigners(t, ctx, 2, msg)
+	commitments := [][]byte{signers[0].commitment, signers[1].commitment}
+	session := signers[0].session
+
+	// a session holding a secret nonce is never exported, in any round
+	_, err := MusigSessionExport(ctx, session)
+	assert.True(t, errors.Is(err, ErrorMusigSessionSecret))
+
+	for _, signer := range signers {
+		_, signer.nonce, _ = signer.session.PublicNonce(ctx, commitments)
+	}
+	_, err = session.Export()
+	assert.True(t, errors.Is(err, ErrorMusigSessionSecret))
+
+	for i, signer := range signers {
+		session.SetNonce(ctx, i, signer.nonce)
+	}
+	session.CombineNonces(ctx)
+	_, err = session.Export()
+	assert.True(t, errors.Is(err, ErrorMusigSessionSecret))
+
+	session.PartialSign(ctx)
+	_, err = session.Export()
+	assert.NoError(t, err)
+}
+
+func TestMusigSessionImportInvalid(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	msg := make([]byte, 32)
+	signers, combined, pkHash := newMusigTestSigners(t, ctx, 2, msg)
+	commitments := [][]byte{signers[0].commitment, signers[1].commitment}
+	for _, signer := range signers {
+		_, signer.nonce, _ = signer.session.PublicNonce(ctx, commitments)
+	}
+
+	_, verifier, _ := MusigSessionInitializeVerifier(ctx, msg, combined, pkHash, commitments)
+	verifier.SetNonce(ctx, 0, signers[0].nonce)
+	partial, _ := verifier.Export()
+
+	verifier.SetNonce(ctx, 1, signers[1].nonce)
+	verifier.CombineNonces(ctx)
+	combinedNonces, _ := verifier.Export()
+
+	session := signers[0].session
+	session.SetNonce(ctx, 0, signers[0].nonce)
+	session.SetNonce(ctx, 1, signers[1].nonce)
+	session.CombineNonces(ctx)
+	session.PartialSign(ctx)
+	signed, _ := session.Export()
+
+	for _, data := range [][]byte{partial, combinedNonces, signed} {
+		_, err := MusigSessionImport(ctx, data)
+		assert.NoError(t, err)
+
+		unknown := append([]byte{}, data...)
+		unknown[0] = MusigSessionVersion + 1
+		_, err = MusigSessionImport(ctx, unknown)
+		assert.True(t, errors.Is(err, ErrorMusigSessionVersion))
+
+		for _, l := range []int{0, 3, 50, len(data) - 1} {
+			_, err = MusigSessionImport(ctx, data[:l])
+			assert.True(t, errors.Is(err, ErrorMusigSessionEncoding), l)
+		}
+		_, err = MusigSessionImport(ctx, append(data, 0))
+		assert.True(t, errors.Is(err, ErrorMusigSessionEncoding))
+
+		// no round but the exported one agrees with the state of the nonces
+		for round := MusigRound(0); round <= MusigRoundSigned+1; round++ {
+			if round == MusigRound(data[musigOffsetRound]) {
+				continue
+			}
+			other := append([]byte{}, data...)
+			other[musigOffsetRound] = byte(round)
+			_, err = MusigSessionImport(ctx, other)
+			assert.True(t, errors.Is(err, ErrorMusigSessionEncoding), round)
+		}
+		// nor with an unknown flag, or without a message
+		for _, flag := range []byte{1 << 5, 1 << 7, musigFlagMessageSet} {
+			other := append([]byte{}, data...)
+			other[musigOffsetFlags] ^= flag
+			_, err = MusigSessionImport(ctx, other)
+			assert.True(t, errors.Is(err, ErrorMusigSessionEncoding), flag)
+		}
+	}
+
+	// a signer whose own nonce is missing
+	other := append([]byte{}, signed...)
+	other[musigOffsetFlags] &^= musigFlagOwnNonce
+	copy(other[musigOffsetSigner-33:musigOffsetSigner], make([]byte, 33))
+	_, err := MusigSessionImport(ctx, other)
+	assert.True(t, errors.Is(err, ErrorMusigSessionEncoding))
+
+	// combined nonces with the nonce of a signer missing
+	other = append([]byte{}, combinedNonces...)
+	other[musigOffsetSigner] = 0
+	copy(other[musigOffsetSigner+1+4+32:musigOffsetSigner+70], make([]byte, 33))
+	_, err = MusigSessionImport(ctx, other)
+	assert.True(t, errors.Is(err, ErrorMusigSessionEncoding))
+
+	// while it is fine before combining them
+	other = append([]byte{}, partial...)
+	assert.Equal(t, byte(0), other[musigOffsetSigner+70])
+	other[musigOffsetSigner] = 0
+	copy(other[musigOffsetSigner+1+4+32:musigOffsetSigner+70], make([]byte, 33))
+	_, err = MusigSessionImport(ctx, other)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,317 @@
+package secp256k1
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+const (
+	// Length of elements byte representations
+	LenPAKEntry    int = 2 * LenCompressed
+	LenGenesisHash int = 32
+
+	// pakEntryPrefix is the prefix of the entries of the PAK list as given
+	// in the configuration of an Elements node, -pak=<offline>:<online>
+	pakEntryPrefix = "pak="
+)
+
+var (
+	ErrorPAKEntry           = errors.New("PAK entry must be the hex encoded offline and online public keys separated by a colon")
+	ErrorPAKEntrySize       = errors.New("PAK entry must be exactly 66 bytes")
+	ErrorPAKListSize        = errors.New("PAK list must hold between 1 and 255 key pairs")
+	ErrorPAKKeyCount        = errors.New("number of keys of the whitelist proof does not match the size of the PAK list")
+	ErrorPAKListNil         = errors.New("PAK list is nil")
+	ErrorPegoutScript       = errors.New("invalid peg-out script")
+	ErrorPegoutNil          = errors.New("peg-out is nil")
+	ErrorGenesisHashSize    = errors.New("genesis hash must be exactly 32 bytes")
+	ErrorPegoutGenesisHash  = errors.New("peg-out is for a different parent chain")
+	ErrorPegoutDestination  = errors.New("peg-out destination must be a P2PKH script")
+	ErrorPegoutPubkeyHash   = errors.New("peg-out destination does not pay to the whitelisted public key")
+	ErrorPegoutProofMissing = errors.New("peg-out has no PAK proof")
+)
+
+// PAKList is the list of pegout authorization keys, the pairs of online and
+// offline public keys of the functionaries of an Elements federation. A
+// peg-out is authorized if its destination key is whitelisted by one of the
+// pairs, see WhitelistSign.
+type PAKList struct {
+	online  []*PublicKey
+	offline []*PublicKey
+}
+
+// NewPAKList returns a PAK list made of the given online and offline public
+// keys, which must have the same length.
+func NewPAKList(onlinePubkeys, offlinePubkeys []*PublicKey) (*PAKList, error) {
+	if len(onlinePubkeys) != len(offlinePubkeys) {
+		return nil, newError("NewPAKList", 0, ErrorWhitelistKeyCount)
+	}
+	if len(onlinePubkeys) < 1 || len(onlinePubkeys) > WhitelistMaxKeys {
+		return nil, newError("NewPAKList", 0, ErrorPAKListSize)
+	}
+	for i := range onlinePubkeys {
+		if onlinePubkeys[i] == nil || offlinePubkeys[i] == nil {
+			return nil, newIndexError("NewPAKList", i, ErrorPublicKeyNil)
+		}
+	}
+
+	list := &PAKList{
+		online:  make([]*PublicKey, len(onlinePubkeys)),
+		offline: make([]*PublicKey, len(offlinePubkeys)),
+	}
+	copy(list.online, onlinePubkeys)
+	copy(list.offline, offlinePubkeys)
+	return list, nil
+}
+
+// PAKListParse parses a PAK list in the format of the configuration of an
+// Elements node: every entry holds the hex encoded offline and online
+// compressed public keys separated by a colon, optionally prefixed by
+// "pak=". The order of the entries matters, since whitelist proofs commit
+// to it.
+func PAKListParse(ctx *Context, entries []string) (*PAKList, error) {
+	if len(entries) < 1 || len(entries) > WhitelistMaxKeys {
+		return nil, newError("PAKListParse", 0, ErrorPAKListSize)
+	}
+
+	list := &PAKList{}
+	for i, entry := range entries {
+		keys := strings.Split(strings.TrimPrefix(strings.TrimSpace(entry), pakEntryPrefix), ":")
+		if len(keys) != 2 {
+			return nil, newIndexError("PAKListParse", i, ErrorPAKEntry)
+		}
+		offline, err := parsePAKKey(ctx, keys[0])
+		if err != nil {
+			return nil, newIndexError("PAKListParse", i, err)
+		}
+		online, err := parsePAKKey(ctx, keys[1])
+		if err != nil {
+			return nil, newIndexError("PAKListParse", i, err)
+		}
+		list.online = append(list.online, online)
+		list.offline = append(list.offline, offline)
+	}
+	return list, nil
+}
+
+// PAKListParseBytes parses a PAK list whose entries are 66 bytes each, the
+// offline compressed public key followed by the online one, as committed to
+// in the blocks of a dynamic federation.
+func PAKListParseBytes(ctx *Context, entries [][]byte) (*PAKList, error) {
+	if len(entries) < 1 || len(entries) > WhitelistMaxKeys {
+		return nil, newError("PAKListParseBytes", 0, ErrorPAKListSize)
+	}
+
+	list := &PAKList{}
+	for i, entry := range entries {
+		if len(entry) != LenPAKEntry {
+			return nil, newIndexError("PAKListParseBytes", i, ErrorPAKEntrySize)
+		}
+		_, offline, err := EcPubkeyParse(ctx, entry[:LenCompressed])
+		if err != nil {
+			return nil, newIndexError("PAKListParseBytes", i, ErrorPAKEntry)
+		}
+		_, online, err := EcPubkeyParse(ctx, entry[LenCompressed:])
+		if err != nil {
+			return nil, newIndexError("PAKListParseBytes", i, ErrorPAKEntry)
+		}
+		list.online = append(list.online, online)
+		list.offline = append(list.offline, offline)
+	}
+	return list, nil
+}
+
+func parsePAKKey(ctx *Context, key string) (*PublicKey, error) {
+	bytes, err := hex.DecodeString(key)
+	if err != nil || len(bytes) != LenCompressed {
+		return nil, ErrorPAKEntry
+	}
+	_, pk, err := EcPubkeyParse(ctx, bytes)
+	if err != nil {
+		return nil, ErrorPAKEntry
+	}
+	return pk, nil
+}
+
+// Len returns the number of key pairs of the list
+func (l *PAKList) Len() int {
+	return len(l.online)
+}
+
+// OnlineKeys returns the online public keys of the list
+func (l *PAKList) OnlineKeys() []*PublicKey {
+	keys := make([]*PublicKey, len(l.online))
+	copy(keys, l.online)
+	return keys
+}
+
+// OfflineKeys returns the offline public keys of the list
+func (l *PAKList) OfflineKeys() []*PublicKey {
+	keys := make([]*PublicKey, len(l.offline))
+	copy(keys, l.offline)
+	return keys
+}
+
+// Entries returns the entries of the list in the format parsed by
+// PAKListParse, without the "pak=" prefix
+func (l *PAKList) Entries() []string {
+	ctx := SharedContext(ContextNone)
+	entries := make([]string, len(l.online))
+	for i := range l.online {
+		_, offline, _ := EcPubkeySerialize(ctx, l.offline[i], EcCompressed)
+		_, online, _ := EcPubkeySerialize(ctx, l.online[i], EcCompressed)
+		entries[i] = hex.EncodeToString(offline) + ":" + hex.EncodeToString(online)
+	}
+	return entries
+}
+
+// Pegout holds the data of an Elements peg-out script:
+//
+//	OP_RETURN <genesis hash> <destination> [<whitelisted key> <PAK proof>]
+//
+// The genesis hash is the one of the parent chain, the destination is the
+// P2PKH script receiving the peg-out on the parent chain, and the proof is
+// the whitelist signature of the public key the destination pays to, derived
+// by tweaking the offline key of a PAK list entry.
+type Pegout struct {
+	GenesisHash []byte
+	Destination []byte
+	Pubkey      *PublicKey
+	Proof       *WhitelistSignature
+}
+
+// PegoutParse parses the script of a peg-out output. The whitelisted key and
+// the proof are nil for a peg-out without PAK proof.
+func PegoutParse(ctx *Context, script []byte) (*Pegout, error) {
+	if len(script) < 1 || script[0] != opReturn {
+		return nil, newError("PegoutParse", 0, ErrorPegoutScript)
+	}
+	pushes, ok := scriptPushes(script[1:])
+	if !ok || (len(pushes) != 2 && len(pushes) != 4) {
+		return nil, newError("PegoutParse", 0, ErrorPegoutScript)
+	}
+	if len(pushes[0]) != LenGenesisHash {
+		return nil, newError("PegoutParse", 0, ErrorPegoutScript)
+	}
+
+	pegout := &Pegout{
+		GenesisHash: pushes[0],
+		Destination: pushes[1],
+	}
+	if len(pushes) == 2 {
+		return pegout, nil
+	}
+
+	if len(pushes[2]) != LenCompressed {
+		return nil, newError("PegoutParse", 0, ErrorPegoutScript)
+	}
+	_, pk, err := EcPubkeyParse(ctx, pushes[2])
+	if err != nil {
+		return nil, err
+	}
+	_, proof, err := WhitelistSignatureParse(ctx, pushes[3])
+	if err != nil {
+		return nil, err
+	}
+	pegout.Pubkey = pk
+	pegout.Proof = proof
+	return pegout, nil
+}
+
+// PegoutVerify checks that a peg-out is authorized by a member of the PAK
+// list, the same way an Elements node does before accepting it. The peg-out
+// must be for the parent chain with the given genesis hash, in the internal
+// byte order in which it is pushed by the script, its destination
+// must be a P2PKH script paying to the whitelisted key, and its proof must
+// be created for exactly as many keys as the PAK list holds, otherwise an
+// error is returned. The context must be initialized for verification. The
+// return code is 1 for a valid proof, 0 for an invalid proof, in which case
+// no error is returned.
+func PegoutVerify(ctx *Context, list *PAKList, genesisHash []byte, pegout *Pegout) (int, error) {
+	if list == nil {
+		return 0, newError("PegoutVerify", 0, ErrorPAKListNil)
+	}
+	if pegout == nil {
+		return 0, newError("PegoutVerify", 0, ErrorPegoutNil)
+	}
+	if len(genesisHash) != LenGenesisHash {
+		return 0, newError("PegoutVerify", 0, ErrorGenesisHashSize)
+	}
+	if !bytes.Equal(pegout.GenesisHash, genesisHash) {
+		return 0, newError("PegoutVerify", 0, ErrorPegoutGenesisHash)
+	}
+	if pegout.Pubkey == nil || pegout.Proof == nil {
+		return 0, newError("PegoutVerify", 0, ErrorPegoutProofMissing)
+	}
+
+	pubkeyHash, ok := p2pkhHash(pegout.Destination)
+	if !ok {
+		return 0, newError("PegoutVerify", 0, ErrorPegoutDestination)
+	}
+	_, pubkey, err := EcPubkeySerialize(ctx, pegout.Pubkey, EcCompressed)
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(hash160(pubkey), pubkeyHash) {
+		return 0, newError("PegoutVerify", 0, ErrorPegoutPubkeyHash)
+	}
+
+	if pegout.Proof.NKeys() != list.Len() {
+		return 0, newError("PegoutVerify", 0, ErrorPAKKeyCount)
+	}
+	return WhitelistVerify(ctx, pegout.Proof, list.online, list.offline, pegout.Pubkey)
+}
+
+// PegoutScriptVerify parses a peg-out script with PegoutParse and verifies
+// it with PegoutVerify.
+func PegoutScriptVerify(ctx *Context, list *PAKList, genesisHash []byte, script []byte) (int, error) {
+	pegout, err := PegoutParse(ctx, script)
+	if err != nil {
+		return 0, err
+	}
+	return PegoutVerify(ctx, list, genesisHash, pegout)
+}
+
+// p2pkhHash returns the public key hash of a P2PKH script
+func p2pkhHash(script []byte) ([]byte, bool) {
+	if len(script) != 25 ||
+		script[0] != opDup || script[1] != opHash160 || script[2] != 20 ||
+		script[23] != opEqualVerify || script[24] != opCheckSig {
+		return nil, false
+	}
+	return script[3:23], true
+}
+
+// scriptPushes returns the data of a script made only of data pushes
+func scriptPushes(script []byte) ([][]byte, bool) {
+	var pushes [][]byte
+	for len(script) > 0 {
+		op := script[0]
+		script = script[1:]
+
+		var size int
+		switch {
+		case op < opPushData1:
+			size = int(op)
+		case op == opPushData1 && len(script) >= 1:
+			size = int(script[0])
+			script = script[1:]
+		case op == opPushData2 && len(script) >= 2:
+			size = int(binary.LittleEndian.Uint16(script))
+			script = script[2:]
+		case op == opPushData4 && len(script) >= 4:
+			size = int(binary.LittleEndian.Uint32(script))
+			script = script[4:]
+		default:
+			return nil, false
+		}
+		if size < 0 || size > len(script) {
+			return nil, false
+		}
+		pushes = append(pushes, script[:size])
+		script = script[size:]
+	}
+	return pushes, true
+}
@@ -0,0 +1,198 @@
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pushData returns the script pushing data
+func pushData(data []byte) []byte {
+	switch {
+	case len(data) < opPushData1:
+		return append([]byte{byte(len(data))}, data...)
+	case len(data) <= 0xff:
+		return append([]byte{opPushData1, byte(len(data))}, data...)
+	default:
+		return append([]byte{opPushData2, byte(len(data)), byte(len(data) >> 8)}, data...)
+	}
+}
+
+// newPegoutScript returns the peg-out script paying to pubkey, whitelisted
+// by proof
+func newPegoutScript(ctx *Context, genesisHash []byte, pubkey *PublicKey, proof *WhitelistSignature) []byte {
+	_, serialized, _ := EcPubkeySerialize(ctx, pubkey, EcCompressed)
+	destination := append([]byte{opDup, opHash160, 20}, hash160(serialized)...)
+	destination = append(destination, opEqualVerify, opCheckSig)
+
+	script := []byte{opReturn}
+	script = append(script, pushData(genesisHash)...)
+	script = append(script, pushData(destination)...)
+	script = append(script, pushData(serialized)...)
+	return append(script, pushData(proof.Serialize())...)
+}
+
+func TestPAKListParse(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	keys := newWhitelistTestKeys(t, ctx, 3)
+	list, err := NewPAKList(keys.online, keys.offline)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := list.Entries()
+	assert.Equal(t, 3, len(entries))
+
+	entries[0] = pakEntryPrefix + entries[0]
+	parsed, err := PAKListParse(ctx, entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, list.Entries(), parsed.Entries())
+
+	raw := make([][]byte, len(entries))
+	for i := range raw {
+		_, offline, _ := EcPubkeySerialize(ctx, keys.offline[i], EcCompressed)
+		_, online, _ := EcPubkeySerialize(ctx, keys.online[i], EcCompressed)
+		raw[i] = append(offline, online...)
+	}
+	parsed, err = PAKListParseBytes(ctx, raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, list.Entries(), parsed.Entries())
+}
+
+func TestPAKListParseInvalid(t *testing.T) {
+	ctx, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(ctx)
+
+	keys := newWhitelistTestKeys(t, ctx, 2)
+	list, _ := NewPAKList(keys.online, keys.offline)
+	entries := list.Entries()
+	offline := entries[0][:2*LenCompressed]
+
+	tests := []struct {
+		entries []string
+		err     error
+	}{
+		{nil, ErrorPAKListSize},
+		{[]string{entries[0], offline}, ErrorPAKEntry},
+		{[]string{offline + ":" + offline + ":" + offline}, ErrorPAKEntry},
+		{[]string{offline + ":zz"}, ErrorPAKEntry},
+		{[]string{offline + ":04" + offline[2:]}, ErrorPAKEntry},
+	}
+
+	for _, tt := range tests {
+		_, err := PAKListParse(ctx, tt.entries)
+		assert.True(t, errors.Is(err, tt.err), err)
+	}
+
+	_, err := NewPAKList(keys.online, keys.offline[:1])
+	assert.True(t, errors.Is(err, ErrorWhitelistKeyCount))
+
+	_, err = PAKListParseBytes(ctx, [][]byte{make([]byte, LenPAKEntry-1)})
+	assert.True(t, errors.Is(err, ErrorPAKEntrySize))
+}
+
+func TestPegoutVerify(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	genesisHash := make([]byte, LenGenesisHash)
+	rand.Read(genesisHash)
+
+	for _, n := range []int{1, 3, 10} {
+		keys := newWhitelistTestKeys(t, ctx, n)
+		list, err := NewPAKList(keys.online, keys.offline)
+		if err != nil {
+			t.Fatal(err)
+		}
+		subSeckey := make([]byte, 32)
+		rand.Read(subSeckey)
+		pubkey, proof := keys.sign(t, ctx, subSeckey, n-1)
+		script := newPegoutScript(ctx, genesisHash, pubkey, proof)
+
+		pegout, err := PegoutParse(ctx, script)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, genesisHash, pegout.GenesisHash)
+		assert.Equal(t, n, pegout.Proof.NKeys())
+
+		result, err := PegoutVerify(ctx, list, genesisHash, pegout)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+
+		// a PAK list with the pairs in a different order rejects the proof
+		if n > 1 {
+			online := append([]*PublicKey{keys.online[n-1]}, keys.online[:n-1]...)
+			offline := append([]*PublicKey{keys.offline[n-1]}, keys.offline[:n-1]...)
+			shuffled, _ := NewPAKList(online, offline)
+			result, err := PegoutScriptVerify(ctx, shuffled, genesisHash, script)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, result)
+		}
+	}
+}
+
+func TestPegoutVerifyInvalid(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	genesisHash := make([]byte, LenGenesisHash)
+	rand.Read(genesisHash)
+
+	keys := newWhitelistTestKeys(t, ctx, 3)
+	list, _ := NewPAKList(keys.online, keys.offline)
+	subSeckey := make([]byte, 32)
+	rand.Read(subSeckey)
+	pubkey, proof := keys.sign(t, ctx, subSeckey, 0)
+	script := newPegoutScript(ctx, genesisHash, pubkey, proof)
+
+	// the proof was created for 3 keys
+	smaller, _ := NewPAKList(keys.online[:2], keys.offline[:2])
+	_, err := PegoutScriptVerify(ctx, smaller, genesisHash, script)
+	assert.True(t, errors.Is(err, ErrorPAKKeyCount), err)
+
+	otherGenesis := bytes.Repeat([]byte{1}, LenGenesisHash)
+	_, err = PegoutScriptVerify(ctx, list, otherGenesis, script)
+	assert.True(t, errors.Is(err, ErrorPegoutGenesisHash), err)
+
+	_, err = PegoutScriptVerify(ctx, list, genesisHash[1:], script)
+	assert.True(t, errors.Is(err, ErrorGenesisHashSize), err)
+
+	// a destination paying to another key
+	_, otherPubkey, _ := EcPubkeyCreate(ctx, keys.onlineSeckeys[0])
+	pegout, _ := PegoutParse(ctx, script)
+	pegout.Pubkey = otherPubkey
+	_, err = PegoutVerify(ctx, list, genesisHash, pegout)
+	assert.True(t, errors.Is(err, ErrorPegoutPubkeyHash), err)
+
+	pegout, _ = PegoutParse(ctx, script)
+	pegout.Destination = append([]byte{0x00, 20}, pegout.Destination[3:23]...)
+	_, err = PegoutVerify(ctx, list, genesisHash, pegout)
+	assert.True(t, errors.Is(err, ErrorPegoutDestination), err)
+
+	// a peg-out without PAK proof
+	noProof := script[:1+1+LenGenesisHash+1+25]
+	pegout, err = PegoutParse(ctx, noProof)
+	assert.NoError(t, err)
+	_, err = PegoutVerify(ctx, list, genesisHash, pegout)
+	assert.True(t, errors.Is(err, ErrorPegoutProofMissing), err)
+
+	for _, invalid := range [][]byte{
+		nil,
+		script[1:],
+		script[:len(script)-1],
+		append(append([]byte{}, script...), 0x51),
+	} {
+		_, err := PegoutParse(ctx, invalid)
+		assert.Error(t, err, hex.EncodeToString(invalid))
+	}
+}
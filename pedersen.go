@@ -7,21 +7,27 @@ package secp256k1
 // static void setBytesArray(unsigned char** a, unsigned char* v, int i) { if (a) a[i] = v; }
 // static unsigned char* getBytesArray(unsigned char** a, int i) { return !a ? NULL : a[i]; }
 // static void freeBytesArray(unsigned char** a) { if (a) free(a); }
+// static const secp256k1_pedersen_commitment** makeCommitmentsArray(int size) { return !size ? NULL : calloc(sizeof(secp256k1_pedersen_commitment*), size); }
+// static void setCommitmentsArray(const secp256k1_pedersen_commitment** a, secp256k1_pedersen_commitment* v, int i) { if (a) a[i] = v; }
+// static void freeCommitmentsArray(const secp256k1_pedersen_commitment** a) { if (a) free(a); }
 import "C"
 import (
 	"encoding/hex"
 	"errors"
-	"unsafe"
+	"runtime"
 )
 
-const (
-	ErrCommitmentParse     string = "unable to parse the data as a commitment"
-	ErrCommitmentSerialize string = "unable to serialize commitment"
-	ErrCommitmentCount     string = "number of elements differ in input arrays"
-	// ErrCommitmentTally     string = "sums of inputs and outputs are not equal"
-	ErrCommitmentCommit   string = "failed to create a commitment"
-	ErrCommitmentBlindSum string = "failed to calculate sum of blinding factors"
-	ErrCommitmentPubkey   string = "failed to create public key from commitment"
+var (
+	ErrCommitmentParse     = errors.New("unable to parse the data as a commitment")
+	ErrCommitmentSize      = errors.New("commitment must be 33 bytes")
+	ErrCommitmentSerialize = errors.New("unable to serialize commitment")
+	ErrCommitmentCount     = errors.New("number of elements differ in input arrays")
+	// ErrCommitmentTally     = errors.New("sums of inputs and outputs are not equal")
+	ErrCommitmentCommit    = errors.New("failed to create a commitment")
+	ErrCommitmentBlindSum  = errors.New("failed to calculate sum of blinding factors")
+	ErrCommitmentBlindSize = errors.New("blinding factor must be 32 bytes")
+	ErrCommitmentPubkey    = errors.New("failed to create public key from commitment")
+	ErrCommitmentNil       = errors.New("commitment is nil")
 )
 
 // Commitment cointains a pointer to opaque data structure that stores a base point
@@ -78,13 +84,26 @@ func CommitmentParse(
 	*Commitment,
 	error,
 ) {
-	commit := newCommitment()
-	if 1 != C.secp256k1_pedersen_commitment_parse(
-		context.ctx,
-		commit.com,
-		cBuf(data33)) {
+	if len(data33) != 33 {
+		return nil, newError("CommitmentParse", 0, ErrCommitmentSize)
+	}
+	if err := context.check(); err != nil {
+		return nil, newError("CommitmentParse", 0, err)
+	}
+	defer runtime.KeepAlive(context)
 
-		return nil, errors.New(ErrCommitmentParse + " \"" + hex.EncodeToString(data33) + "\"")
+	commit := newCommitment()
+	result, err := call("secp256k1_pedersen_commitment_parse", func() C.int {
+		return C.secp256k1_pedersen_commitment_parse(
+			context.ctx,
+			commit.com,
+			cBuf(data33))
+	})
+	if err != nil {
+		return nil, newError("CommitmentParse", 0, err)
+	}
+	if 1 != result {
+		return nil, newError("CommitmentParse", result, ErrCommitmentParse)
 	}
 
 	return commit, nil
@@ -102,12 +121,24 @@ func CommitmentSerialize(
 	data [33]byte,
 	err error,
 ) {
-	if 1 != C.secp256k1_pedersen_commitment_serialize(
-		context.ctx,
-		cBuf(data[:]),
-		commit.com) {
+	if err = context.check(); err != nil {
+		err = newError("CommitmentSerialize", 0, err)
+		return
+	}
+	defer runtime.KeepAlive(context)
 
-		err = errors.New(ErrCommitmentSerialize)
+	result, err := call("secp256k1_pedersen_commitment_serialize", func() C.int {
+		return C.secp256k1_pedersen_commitment_serialize(
+			context.ctx,
+			cBuf(data[:]),
+			commit.com)
+	})
+	if err != nil {
+		err = newError("CommitmentSerialize", 0, err)
+		return
+	}
+	if 1 != result {
+		err = newError("CommitmentSerialize", result, ErrCommitmentSerialize)
 	}
 	return
 }
@@ -136,19 +167,45 @@ func Commit(
 	commit *Commitment,
 	err error,
 ) {
-	commit = newCommitment()
-	if 1 != C.secp256k1_pedersen_commit(
-		context.ctx,
-		commit.com,
-		cBuf(blind),
-		C.uint64_t(value),
-		valuegen.gen) {
+	if len(blind) != 32 {
+		return nil, newError("Commit", 0, ErrCommitmentBlindSize)
+	}
+	if err = context.require(ContextSign); err != nil {
+		return nil, newError("Commit", 0, err)
+	}
+	defer runtime.KeepAlive(context)
 
-		return nil, errors.New(ErrCommitmentCommit)
+	commit = newCommitment()
+	result, err := call("secp256k1_pedersen_commit", func() C.int {
+		return C.secp256k1_pedersen_commit(
+			context.ctx,
+			commit.com,
+			cBuf(blind),
+			C.uint64_t(value),
+			valuegen.gen)
+	})
+	if err != nil {
+		return nil, newError("Commit", 0, err)
+	}
+	if 1 != result {
+		return nil, newError("Commit", result, ErrCommitmentCommit)
 	}
 	return
 }
 
+// CommitSecret is Commit with a blinding factor held in a SecretScalar
+func CommitSecret(
+	context *Context,
+	blind *SecretScalar,
+	value uint64,
+	valuegen *Generator,
+) (*Commitment, error) {
+	if blind == nil {
+		return nil, newError("CommitSecret", 0, ErrorSecretScalarNil)
+	}
+	return Commit(context, blind[:], value, valuegen)
+}
+
 // BlindSum computes the sum of multiple positive and negative blinding factors.
 //
 //  Returns 1: Sum successfully computed.
@@ -171,6 +228,56 @@ func BlindSum(
 	sum [32]byte,
 	err error,
 ) {
+	err = blindSum("BlindSum", context, posblinds, negblinds, (*SecretScalar)(&sum))
+	return
+}
+
+// BlindSumSecret is BlindSum with the blinding factors and their sum held in
+// SecretScalars, which are not copied.
+func BlindSumSecret(
+	context *Context,
+	posblinds []*SecretScalar,
+	negblinds []*SecretScalar,
+) (*SecretScalar, error) {
+	pos, err := secretSlices("BlindSumSecret", 0, posblinds)
+	if err != nil {
+		return nil, err
+	}
+	neg, err := secretSlices("BlindSumSecret", len(posblinds), negblinds)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := new(SecretScalar)
+	if err := blindSum("BlindSumSecret", context, pos, neg, sum); err != nil {
+		return nil, err
+	}
+	return sum, nil
+}
+
+func blindSum(
+	op string,
+	context *Context,
+	posblinds [][]byte,
+	negblinds [][]byte,
+	sum *SecretScalar,
+) error {
+	for i, b := range posblinds {
+		if len(b) != 32 {
+			return newIndexError(op, i, ErrCommitmentBlindSize)
+		}
+	}
+	for i, b := range negblinds {
+		if len(b) != 32 {
+			return newIndexError(op, len(posblinds)+i, ErrCommitmentBlindSize)
+		}
+	}
+
+	if err := context.check(); err != nil {
+		return newError(op, 0, err)
+	}
+	defer runtime.KeepAlive(context)
+
 	npositive := len(posblinds)
 	ntotal := npositive + len(negblinds)
 
@@ -185,17 +292,36 @@ func BlindSum(
 		C.setBytesArray(blinds, cBuf(nb), C.int(npositive+ni))
 	}
 
-	if 1 != C.secp256k1_pedersen_blind_sum(
-		context.ctx,
-		cBuf(sum[:]),
-		blinds,
-		C.size_t(C.int(ntotal)),
-		C.size_t(C.int(npositive))) {
-
-		err = errors.New("error calculating sum of blinds")
+	result, err := call("secp256k1_pedersen_blind_sum", func() C.int {
+		return C.secp256k1_pedersen_blind_sum(
+			context.ctx,
+			cBuf(sum[:]),
+			blinds,
+			C.size_t(C.int(ntotal)),
+			C.size_t(C.int(npositive)))
+	})
+	if err != nil {
+		sum.Zero()
+		return newError(op, 0, err)
 	}
+	if 1 != result {
+		sum.Zero()
+		return newError(op, result, ErrCommitmentBlindSum)
+	}
+	return nil
+}
 
-	return
+// secretSlices returns views of the scalars, for the functions taking
+// [][]byte secrets, with the index of a nil scalar offset by first.
+func secretSlices(op string, first int, scalars []*SecretScalar) ([][]byte, error) {
+	slices := make([][]byte, len(scalars))
+	for i, s := range scalars {
+		if s == nil {
+			return nil, newIndexError(op, first+i, ErrorSecretScalarNil)
+		}
+		slices[i] = s[:]
+	}
+	return slices, nil
 }
 
 // BlindGeneratorBlindSum sets the final Pedersen blinding factor correctly
@@ -238,15 +364,64 @@ func BlindGeneratorBlindSum(
 	blindout [32]byte,
 	err error,
 ) {
+	err = blindGeneratorBlindSum("BlindGeneratorBlindSum", context, value, generatorblind, blindingfactor, ninputs, (*SecretScalar)(&blindout))
+	return
+}
+
+// BlindGeneratorBlindSumSecret is BlindGeneratorBlindSum with the blinding
+// factors held in SecretScalars, which are not copied.
+func BlindGeneratorBlindSumSecret(
+	context *Context,
+	value []uint64,
+	generatorblind []*SecretScalar,
+	blindingfactor []*SecretScalar,
+	ninputs int,
+) (*SecretScalar, error) {
+	gbls, err := secretSlices("BlindGeneratorBlindSumSecret", 0, generatorblind)
+	if err != nil {
+		return nil, err
+	}
+	fbls, err := secretSlices("BlindGeneratorBlindSumSecret", len(generatorblind), blindingfactor)
+	if err != nil {
+		return nil, err
+	}
+
+	blindout := new(SecretScalar)
+	err = blindGeneratorBlindSum("BlindGeneratorBlindSumSecret", context, value, gbls, fbls, ninputs, blindout)
+	if err != nil {
+		return nil, err
+	}
+	return blindout, nil
+}
+
+func blindGeneratorBlindSum(
+	op string,
+	context *Context,
+	value []uint64,
+	generatorblind [][]byte,
+	blindingfactor [][]byte,
+	ninputs int,
+	blindout *SecretScalar,
+) error {
 	vbl := len(value)
 	gbl := len(generatorblind)
 	fbl := len(blindingfactor)
 
 	if vbl != gbl || gbl != (fbl+1) {
-		err = errors.New(ErrCommitmentCount)
-		return
+		return newError(op, 0, ErrCommitmentCount)
+	}
+	for i := 0; i < vbl; i++ {
+		if len(generatorblind[i]) != 32 || (i != fbl && len(blindingfactor[i]) != 32) {
+			return newIndexError(op, i, ErrCommitmentBlindSize)
+		}
 	}
 
+	if err := context.check(); err != nil {
+		return newError(op, 0, err)
+	}
+	defer runtime.KeepAlive(context)
+
+	// the last blinding factor is computed in place, directly in blindout
 	gbls := C.makeBytesArray(C.int(vbl))
 	fbls := C.makeBytesArray(C.int(vbl))
 	for i := 0; i < vbl; i++ {
@@ -254,29 +429,144 @@ func BlindGeneratorBlindSum(
 		if i != fbl {
 			C.setBytesArray(fbls, cBuf(blindingfactor[i]), C.int(i))
 		} else {
-			out := make([]byte, 32)
-			C.setBytesArray(fbls, cBuf(out), C.int(i))
+			C.setBytesArray(fbls, cBuf(blindout[:]), C.int(i))
 		}
 	}
 	defer C.freeBytesArray(gbls)
 	defer C.freeBytesArray(fbls)
 
-	if 1 != C.secp256k1_pedersen_blind_generator_blind_sum(
-		context.ctx,
-		u64Arr(value),
-		gbls,
-		fbls,
-		C.size_t(vbl),
-		C.size_t(ninputs)) {
-		err = errors.New(ErrCommitmentCommit)
-		return
+	result, err := call("secp256k1_pedersen_blind_generator_blind_sum", func() C.int {
+		return C.secp256k1_pedersen_blind_generator_blind_sum(
+			context.ctx,
+			u64Arr(value),
+			gbls,
+			fbls,
+			C.size_t(vbl),
+			C.size_t(ninputs))
+	})
+	if err != nil {
+		blindout.Zero()
+		return newError(op, 0, err)
+	}
+	if 1 != result {
+		blindout.Zero()
+		return newError(op, result, ErrCommitmentCommit)
 	}
+	return nil
+}
 
-	results := make([][32]byte, vbl)
-	for i := 0; i < vbl; i++ {
-		b := C.getBytesArray(fbls, C.int(i))
-		copy(results[i][:], C.GoBytes(unsafe.Pointer(b), 32))
+// VerifyTally verifies that a set of positive commitments (i.e. the inputs of
+// a transaction) sums to the same value as a set of negative commitments
+// (i.e. the outputs).
+//
+//  Returns: true: the sums are equal.
+//           false: the sums differ, a commitment is nil or the context has
+//                  been destroyed.
+//  In:     ctx:      pointer to a context object (cannot be NULL)
+//          commits:  pointer to array of pointers to the positive commitments.
+//          pcnt:     number of commitments pointed to by commits.
+//          ncommits: pointer to array of pointers to the negative commitments.
+//          ncnt:     number of commitments pointed to by ncommits.
+//
+// This computes sum(commit[0..pcnt)) - sum(ncommit[0..ncnt)) == 0.
+//
+// A Pedersen commitment is xG + vA where G and A are both generators for the
+// secp256k1 group. By requiring that the sum of the blinding factors x, and
+// the sum of the values v, are equal on both sides, this ensures that no
+// value is created or destroyed, as long as all the commitments carry a
+// valid range proof.
+func VerifyTally(
+	context *Context,
+	positive []*Commitment,
+	negative []*Commitment,
+) bool {
+	if context.check() != nil {
+		return false
 	}
-	blindout = results[fbl]
-	return
+	defer runtime.KeepAlive(context)
+
+	for _, commit := range positive {
+		if commit == nil {
+			return false
+		}
+	}
+	for _, commit := range negative {
+		if commit == nil {
+			return false
+		}
+	}
+
+	pcnt := len(positive)
+	ncnt := len(negative)
+
+	commits := C.makeCommitmentsArray(C.int(pcnt))
+	defer C.freeCommitmentsArray(commits)
+	for i, commit := range positive {
+		C.setCommitmentsArray(commits, commit.com, C.int(i))
+	}
+
+	ncommits := C.makeCommitmentsArray(C.int(ncnt))
+	defer C.freeCommitmentsArray(ncommits)
+	for i, commit := range negative {
+		C.setCommitmentsArray(ncommits, commit.com, C.int(i))
+	}
+
+	result, err := call("secp256k1_pedersen_verify_tally", func() C.int {
+		return C.secp256k1_pedersen_verify_tally(
+			context.ctx,
+			commits,
+			C.size_t(pcnt),
+			ncommits,
+			C.size_t(ncnt))
+	})
+	runtime.KeepAlive(positive)
+	runtime.KeepAlive(negative)
+
+	return err == nil && 1 == result
 }
+
+// VerifyTallyWithFees is like VerifyTally, but also accounts for the given
+// explicit amounts (i.e. the fees of a transaction) on the negative side.
+// Each amount is committed with a zero blinding factor under the given value
+// generator, therefore the context must be initialized for signing. Zero
+// amounts are skipped since they would commit to the point at infinity.
+// The returned error reports an unusable context or a nil commitment,
+// while a tally that does not balance just returns false.
+func VerifyTallyWithFees(
+	context *Context,
+	positive []*Commitment,
+	negative []*Commitment,
+	fees []uint64,
+	feegen *Generator,
+) (bool, error) {
+	if err := context.require(ContextSign); err != nil {
+		return false, newError("VerifyTallyWithFees", 0, err)
+	}
+	for i, commit := range positive {
+		if commit == nil {
+			return false, newIndexError("VerifyTallyWithFees", i, ErrCommitmentNil)
+		}
+	}
+	for i, commit := range negative {
+		if commit == nil {
+			return false, newIndexError("VerifyTallyWithFees", len(positive)+i, ErrCommitmentNil)
+		}
+	}
+
+	var zeroBlind [32]byte
+	outputs := make([]*Commitment, 0, len(negative)+len(fees))
+	outputs = append(outputs, negative...)
+	for _, fee := range fees {
+		if fee == 0 {
+			continue
+		}
+		commit, err := Commit(context, zeroBlind[:], fee, feegen)
+		if err != nil {
+			return false, err
+		}
+		outputs = append(outputs, commit)
+	}
+
+	return VerifyTally(context, positive, outputs), nil
+}
+
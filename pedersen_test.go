@@ -3,6 +3,7 @@ package secp256k1
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"testing"
@@ -34,6 +35,11 @@ func TestPedersenCommitmentParseAndSerialize(t *testing.T) {
 			test,
 		),
 	)
+
+	for _, b := range [][]byte{nil, com[:32], append(com, 0)} {
+		_, err = CommitmentParse(ctx, b)
+		assert.True(t, errors.Is(err, ErrCommitmentSize))
+	}
 }
 
 func TestPedersenCommitmentCommit(t *testing.T) {
@@ -62,6 +68,8 @@ func TestPedersenCommitmentCommit(t *testing.T) {
 		assert.NotNil(t, commit)
 		assert.Equal(t, v["expected"].(string), commit.String())
 
+		_, err = Commit(ctx, blindingFactor[:31], value, blindingGenerator)
+		assert.True(t, errors.Is(err, ErrCommitmentBlindSize))
 	}
 }
 
@@ -138,3 +146,77 @@ func TestPedersenBlindGeneratorBlindSum(t *testing.T) {
 		assert.Equal(t, v.Expected, hex.EncodeToString(res[:]))
 	}
 }
+
+func TestPedersenVerifyTally(t *testing.T) {
+	file, err := ioutil.ReadFile("testdata/pedersen.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testVectorType struct {
+		Commits         []string `json:"commits"`
+		NegativeCommits []string `json:"negativeCommits"`
+		Expected        bool     `json:"expected"`
+	}
+	type testType struct {
+		Vectors []testVectorType `json:"verifySum"`
+	}
+
+	var test testType
+	json.Unmarshal(file, &test)
+
+	ctx, _ := ContextCreate(ContextNone)
+	defer ContextDestroy(ctx)
+
+	for _, v := range test.Vectors {
+		commits := []*Commitment{}
+		for _, c := range v.Commits {
+			commit, err := CommitmentFromString(c)
+			assert.NoError(t, err)
+			commits = append(commits, commit)
+		}
+		negativeCommits := []*Commitment{}
+		for _, c := range v.NegativeCommits {
+			commit, err := CommitmentFromString(c)
+			assert.NoError(t, err)
+			negativeCommits = append(negativeCommits, commit)
+		}
+
+		assert.Equal(t, v.Expected, VerifyTally(ctx, commits, negativeCommits))
+		assert.Equal(t, v.Expected, VerifyTally(ctx, negativeCommits, commits))
+	}
+	assert.True(t, VerifyTally(ctx, nil, nil))
+	assert.False(t, VerifyTally(ctx, []*Commitment{nil}, nil))
+}
+
+func TestPedersenVerifyTallyWithFees(t *testing.T) {
+	ctx, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(ctx)
+
+	blind := testingRand32()
+	input, err := Commit(ctx, blind[:], 100, &GeneratorH)
+	assert.NoError(t, err)
+	output, err := Commit(ctx, blind[:], 90, &GeneratorH)
+	assert.NoError(t, err)
+
+	ok, err := VerifyTallyWithFees(ctx, []*Commitment{input}, []*Commitment{output}, []uint64{10}, &GeneratorH)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyTallyWithFees(ctx, []*Commitment{input}, []*Commitment{output}, []uint64{4, 0, 6}, &GeneratorH)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = VerifyTallyWithFees(ctx, []*Commitment{input}, []*Commitment{output}, []uint64{9}, &GeneratorH)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, VerifyTally(ctx, []*Commitment{input}, []*Commitment{output}))
+
+	_, err = VerifyTallyWithFees(ctx, []*Commitment{input}, []*Commitment{nil}, nil, &GeneratorH)
+	assert.True(t, errors.Is(err, ErrCommitmentNil))
+
+	verify, _ := ContextCreate(ContextVerify)
+	defer ContextDestroy(verify)
+	_, err = VerifyTallyWithFees(verify, []*Commitment{input}, []*Commitment{output}, []uint64{10}, &GeneratorH)
+	assert.True(t, errors.Is(err, ErrContextCapability))
+}
@@ -0,0 +1,158 @@
+package secp256k1
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultPoolMaxIdle is the number of idle contexts per flag kept by the
+	// default pool
+	DefaultPoolMaxIdle = 16
+	// DefaultPoolRandomizeInterval is the interval after which the contexts
+	// of the default pool are randomized again
+	DefaultPoolRandomizeInterval = 5 * time.Minute
+)
+
+var (
+	// ErrContextPoolClosed is returned when borrowing from a closed pool
+	ErrContextPoolClosed = errors.New("context pool is closed")
+	// ErrContextPoolForeign is returned when a context that was not borrowed
+	// from the pool is given back to it
+	ErrContextPoolForeign = errors.New("context was not borrowed from this pool")
+)
+
+var defaultPool = NewContextPool(DefaultPoolMaxIdle, DefaultPoolRandomizeInterval)
+
+// pooledContext holds a context together with the time of its last
+// randomization.
+type pooledContext struct {
+	ctx        *Context
+	flags      uint
+	randomized time.Time
+}
+
+// ContextPool hands out contexts initialized with a given set of flags and
+// keeps them randomized. Each context is randomized with crypto/rand when it
+// is created and again, when it is borrowed, if more than the pool interval
+// has elapsed since its last randomization. A borrowed context is owned by
+// the caller until it is given back with Put, therefore it must not be
+// shared among goroutines in the meantime.
+// A ContextPool is safe for concurrent use.
+type ContextPool struct {
+	maxIdle  int
+	interval time.Duration
+
+	mu       sync.Mutex
+	closed   bool
+	idle     map[uint][]*pooledContext
+	borrowed map[*Context]*pooledContext
+}
+
+// NewContextPool returns a pool that keeps at most maxIdle unused contexts
+// for every set of flags, and randomizes them again once interval has
+// elapsed. An interval of zero randomizes the contexts every time they are
+// borrowed.
+func NewContextPool(maxIdle int, interval time.Duration) *ContextPool {
+	return &ContextPool{
+		maxIdle:  maxIdle,
+		interval: interval,
+		idle:     make(map[uint][]*pooledContext),
+		borrowed: make(map[*Context]*pooledContext),
+	}
+}
+
+// Get borrows a context initialized with the given flags, creating a new one
+// if none is idle. The context must be given back with Put once done.
+func (p *ContextPool) Get(flags uint) (*Context, error) {
+	flags = flags&ContextBoth | ContextNone
+
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, newError("ContextPool.Get", 0, ErrContextPoolClosed)
+	}
+	var entry *pooledContext
+	if l := len(p.idle[flags]); l > 0 {
+		entry = p.idle[flags][l-1]
+		p.idle[flags] = p.idle[flags][:l-1]
+	}
+	p.mu.Unlock()
+
+	if entry == nil {
+		ctx, err := ContextCreate(flags)
+		if err != nil {
+			return nil, err
+		}
+		entry = &pooledContext{ctx: ctx, flags: flags}
+	}
+
+	if entry.randomized.IsZero() || time.Since(entry.randomized) >= p.interval {
+		if err := randomizeContext(entry.ctx); err != nil {
+			ContextDestroy(entry.ctx)
+			return nil, newError("ContextPool.Get", 0, err)
+		}
+		entry.randomized = time.Now()
+	}
+
+	p.mu.Lock()
+	p.borrowed[entry.ctx] = entry
+	p.mu.Unlock()
+
+	return entry.ctx, nil
+}
+
+// Put gives back a context previously borrowed with Get. The context is
+// destroyed if the pool is closed or already holds enough idle contexts.
+// An error is returned if the caller already destroyed the context, which
+// the pool then forgets.
+func (p *ContextPool) Put(ctx *Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	entry, ok := p.borrowed[ctx]
+	if !ok {
+		return newError("ContextPool.Put", 0, ErrContextPoolForeign)
+	}
+	delete(p.borrowed, ctx)
+
+	// a context destroyed by the caller is dropped rather than handed out
+	// again
+	if err := ctx.check(); err != nil {
+		return newError("ContextPool.Put", 0, err)
+	}
+	if p.closed || len(p.idle[entry.flags]) >= p.maxIdle {
+		ContextDestroy(ctx)
+		return nil
+	}
+	p.idle[entry.flags] = append(p.idle[entry.flags], entry)
+	return nil
+}
+
+// Close destroys all the idle contexts of the pool. Contexts still borrowed
+// are destroyed when they are given back.
+func (p *ContextPool) Close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.closed = true
+	for flags, entries := range p.idle {
+		for _, entry := range entries {
+			ContextDestroy(entry.ctx)
+		}
+		delete(p.idle, flags)
+	}
+}
+
+// BorrowContext borrows a context initialized with the given flags from the
+// package default pool
+func BorrowContext(flags uint) (*Context, error) {
+	return defaultPool.Get(flags)
+}
+
+// ReturnContext gives back to the package default pool a context obtained
+// with BorrowContext
+func ReturnContext(ctx *Context) error {
+	return defaultPool.Put(ctx)
+}
@@ -0,0 +1,87 @@
+package secp256k1
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedContextConcurrent(t *testing.T) {
+	const n = 32
+	contexts := make([]*Context, n)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			contexts[i] = SharedContext(ContextVerify)
+			_, err := CommitmentFromString("09c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < n; i++ {
+		assert.True(t, contexts[0] == contexts[i])
+	}
+}
+
+func TestContextPool(t *testing.T) {
+	pool := NewContextPool(1, 0)
+
+	ctx, err := pool.Get(ContextSign)
+	assert.NoError(t, err)
+	assert.NotNil(t, ctx)
+
+	blind := testingRand32()
+	_, err = Commit(ctx, blind[:], 10, &GeneratorH)
+	assert.NoError(t, err)
+
+	assert.NoError(t, pool.Put(ctx))
+	assert.Error(t, pool.Put(ctx))
+
+	other, err := pool.Get(ContextSign)
+	assert.NoError(t, err)
+	assert.True(t, ctx == other)
+
+	foreign, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(foreign)
+	assert.Error(t, pool.Put(foreign))
+
+	// a destroyed context is not given back
+	ContextDestroy(other)
+	err = pool.Put(other)
+	assert.True(t, errors.Is(err, ErrContextDestroyed))
+	other, err = pool.Get(ContextSign)
+	assert.NoError(t, err)
+	assert.NoError(t, other.check())
+
+	pool.Close()
+	assert.NoError(t, pool.Put(other))
+
+	_, err = pool.Get(ContextSign)
+	assert.Error(t, err)
+}
+
+func TestContextPoolConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 32; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, err := BorrowContext(ContextBoth)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer ReturnContext(ctx)
+
+			blind := testingRand32()
+			_, err = Commit(ctx, blind[:], 10, &GeneratorH)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
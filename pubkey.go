@@ -0,0 +1,106 @@
+package secp256k1
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+)
+
+var (
+	ErrorPublicKeyHybrid   = errors.New("hybrid public keys are not allowed")
+	ErrorPublicKeyEncoding = errors.New("public key must be hex encoded")
+)
+
+// EcPubkeyParseStrict parses a public key like EcPubkeyParse, but it only
+// accepts the compressed (33 bytes, header byte 0x02 or 0x03) and the
+// uncompressed (65 bytes, header byte 0x04) formats, rejecting the hybrid
+// one (65 bytes, header byte 0x06 or 0x07), which is not standard and would
+// allow different encodings of the same key where consensus code expects
+// only one. The return code is 1 if the public key was fully valid, or 0 if
+// the public key was invalid or could not be parsed.
+func EcPubkeyParseStrict(ctx *Context, publicKey []byte) (int, *PublicKey, error) {
+	if len(publicKey) == LenUncompressed && (publicKey[0] == 0x06 || publicKey[0] == 0x07) {
+		return 0, nil, newError("EcPubkeyParseStrict", 0, ErrorPublicKeyHybrid)
+	}
+	if len(publicKey) != LenCompressed && len(publicKey) != LenUncompressed {
+		return 0, nil, newError("EcPubkeyParseStrict", 0, ErrorPublicKeySize)
+	}
+	return EcPubkeyParse(ctx, publicKey)
+}
+
+// Bytes returns the compressed serialization of the public key if compressed
+// is true, the uncompressed one otherwise
+func (pk *PublicKey) Bytes(compressed bool) []byte {
+	flags := EcUncompressed
+	if compressed {
+		flags = EcCompressed
+	}
+	_, bytes, _ := EcPubkeySerialize(SharedContext(ContextNone), pk, flags)
+	return bytes
+}
+
+// String returns the hex encoded compressed serialization of the public key
+func (pk *PublicKey) String() string {
+	return hex.EncodeToString(pk.Bytes(true))
+}
+
+// PublicKeyFromString parses a hex encoded compressed or uncompressed public
+// key, see EcPubkeyParseStrict
+func PublicKeyFromString(str string) (*PublicKey, error) {
+	bytes, err := hex.DecodeString(str)
+	if err != nil {
+		return nil, newError("PublicKeyFromString", 0, ErrorPublicKeyEncoding)
+	}
+	_, pk, err := EcPubkeyParseStrict(SharedContext(ContextNone), bytes)
+	return pk, err
+}
+
+// PublicKeyFromBytes parses a compressed or uncompressed public key, see
+// EcPubkeyParseStrict
+func PublicKeyFromBytes(bytes []byte) (*PublicKey, error) {
+	_, pk, err := EcPubkeyParseStrict(SharedContext(ContextNone), bytes)
+	return pk, err
+}
+
+// Equal reports whether two public keys are the same point, whatever the
+// format they were parsed from
+func (pk *PublicKey) Equal(other *PublicKey) bool {
+	if pk == nil || other == nil {
+		return pk == other
+	}
+	return bytes.Equal(pk.Bytes(true), other.Bytes(true))
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the public key
+// as String does
+func (pk *PublicKey) MarshalText() ([]byte, error) {
+	return []byte(pk.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, parsing the public key
+// with PublicKeyFromString
+func (pk *PublicKey) UnmarshalText(text []byte) error {
+	parsed, err := PublicKeyFromString(string(text))
+	if err != nil {
+		return err
+	}
+	pk.pk = parsed.pk
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler, encoding the public key as a JSON
+// string holding its hex encoded compressed serialization
+func (pk *PublicKey) MarshalJSON() ([]byte, error) {
+	return json.Marshal(pk.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler, parsing a JSON string with
+// PublicKeyFromString
+func (pk *PublicKey) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return newError("PublicKey.UnmarshalJSON", 0, ErrorPublicKeyEncoding)
+	}
+	return pk.UnmarshalText([]byte(str))
+}
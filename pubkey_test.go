@@ -0,0 +1,117 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testGeneratorCompressed   = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	testGeneratorUncompressed = "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+	testGeneratorHybrid       = "0679be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+)
+
+func TestPublicKeyFromString(t *testing.T) {
+	compressed, err := PublicKeyFromString(testGeneratorCompressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uncompressed, err := PublicKeyFromString(testGeneratorUncompressed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.True(t, compressed.Equal(uncompressed))
+	assert.Equal(t, testGeneratorCompressed, uncompressed.String())
+	assert.Equal(t, testGeneratorUncompressed, hex.EncodeToString(compressed.Bytes(false)))
+	assert.Equal(t, testGeneratorCompressed, hex.EncodeToString(compressed.Bytes(true)))
+
+	fromBytes, err := PublicKeyFromBytes(compressed.Bytes(false))
+	assert.NoError(t, err)
+	assert.True(t, fromBytes.Equal(compressed))
+
+	_, err = PublicKeyFromString("zz")
+	assert.True(t, errors.Is(err, ErrorPublicKeyEncoding))
+	_, err = PublicKeyFromString(testGeneratorCompressed[:64])
+	assert.True(t, errors.Is(err, ErrorPublicKeySize))
+}
+
+func TestPublicKeyEqual(t *testing.T) {
+	ctx, _ := ContextCreate(ContextSign)
+	defer ContextDestroy(ctx)
+
+	g, _ := PublicKeyFromString(testGeneratorCompressed)
+	seckey := make([]byte, 32)
+	seckey[31] = 2
+	_, twoG, _ := EcPubkeyCreate(ctx, seckey)
+
+	assert.False(t, g.Equal(twoG))
+	assert.False(t, g.Equal(nil))
+	assert.True(t, (*PublicKey)(nil).Equal(nil))
+}
+
+func TestEcPubkeyParseStrict(t *testing.T) {
+	ctx, _ := ContextCreate(ContextNone)
+	defer ContextDestroy(ctx)
+
+	hybrid, _ := hex.DecodeString(testGeneratorHybrid)
+
+	// the lax parser accepts hybrid keys
+	_, pk, err := EcPubkeyParse(ctx, hybrid)
+	assert.NoError(t, err)
+	assert.Equal(t, testGeneratorCompressed, pk.String())
+
+	result, _, err := EcPubkeyParseStrict(ctx, hybrid)
+	assert.Equal(t, 0, result)
+	assert.True(t, errors.Is(err, ErrorPublicKeyHybrid))
+
+	_, err = PublicKeyFromString(testGeneratorHybrid)
+	assert.True(t, errors.Is(err, ErrorPublicKeyHybrid))
+
+	for _, key := range []string{testGeneratorCompressed, testGeneratorUncompressed} {
+		b, _ := hex.DecodeString(key)
+		result, _, err := EcPubkeyParseStrict(ctx, b)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+	}
+
+	invalid, _ := hex.DecodeString(testGeneratorCompressed)
+	invalid[0] = 0x05
+	_, _, err = EcPubkeyParseStrict(ctx, invalid)
+	assert.True(t, errors.Is(err, ErrorPublicKeyParse))
+}
+
+func TestPublicKeyJSON(t *testing.T) {
+	g, _ := PublicKeyFromString(testGeneratorUncompressed)
+
+	type document struct {
+		Key  *PublicKey            `json:"key"`
+		Keys map[string]*PublicKey `json:"keys"`
+	}
+	doc := document{Key: g, Keys: map[string]*PublicKey{"g": g}}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, `{"key":"`+testGeneratorCompressed+`","keys":{"g":"`+testGeneratorCompressed+`"}}`, string(data))
+
+	var decoded document
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, g.Equal(decoded.Key))
+	assert.True(t, g.Equal(decoded.Keys["g"]))
+
+	text, err := g.MarshalText()
+	assert.NoError(t, err)
+	var fromText PublicKey
+	assert.NoError(t, fromText.UnmarshalText(text))
+	assert.True(t, g.Equal(&fromText))
+
+	assert.Error(t, json.Unmarshal([]byte(`{"key":"`+testGeneratorHybrid+`"}`), &decoded))
+	assert.Error(t, json.Unmarshal([]byte(`{"key":1}`), &decoded))
+}
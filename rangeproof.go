@@ -8,16 +8,19 @@ package secp256k1
 import "C"
 import (
 	"errors"
+	"runtime"
 	"unsafe"
 )
 
 const (
 	// MaxRangeProofSize is the max size in bytes of a range proof
 	MaxRangeProofSize = 5134
+)
 
-	ErrRangeProof       string = "failed to create a range proof"
-	ErrRangeProofInfo   string = "failed to retrieve info for range proof"
-	ErrRangeProofRewind string = "failed to recover information about author of range proof"
+var (
+	ErrRangeProof       = errors.New("failed to create a range proof")
+	ErrRangeProofInfo   = errors.New("failed to retrieve info for range proof")
+	ErrRangeProofRewind = errors.New("failed to recover information about author of range proof")
 )
 
 // RangeProofSign authors a proof that a committed value is within a range.
@@ -55,6 +58,50 @@ func RangeProofSign(
 	extraCommit []byte,
 	generator *Generator,
 ) ([]byte, error) {
+	// the arguments are copies of the secrets of the caller
+	defer zero(blindingFactor[:])
+	defer zero(nonce[:])
+	return rangeProofSign("RangeProofSign", context, minValue, commit, (*SecretScalar)(&blindingFactor), (*SecretScalar)(&nonce), exp, minBits, value, message, extraCommit, generator)
+}
+
+// RangeProofSignSecret is RangeProofSign with the blinding factor and the
+// nonce held in SecretScalars, which are not copied.
+func RangeProofSignSecret(
+	context *Context,
+	minValue uint64,
+	commit *Commitment,
+	blindingFactor *SecretScalar,
+	nonce *SecretScalar,
+	exp, minBits int,
+	value uint64,
+	message []byte,
+	extraCommit []byte,
+	generator *Generator,
+) ([]byte, error) {
+	if blindingFactor == nil || nonce == nil {
+		return nil, newError("RangeProofSignSecret", 0, ErrorSecretScalarNil)
+	}
+	return rangeProofSign("RangeProofSignSecret", context, minValue, commit, blindingFactor, nonce, exp, minBits, value, message, extraCommit, generator)
+}
+
+func rangeProofSign(
+	op string,
+	context *Context,
+	minValue uint64,
+	commit *Commitment,
+	blindingFactor *SecretScalar,
+	nonce *SecretScalar,
+	exp, minBits int,
+	value uint64,
+	message []byte,
+	extraCommit []byte,
+	generator *Generator,
+) ([]byte, error) {
+	if err := context.require(ContextBoth); err != nil {
+		return nil, newError(op, 0, err)
+	}
+	defer runtime.KeepAlive(context)
+
 	proof := make([]byte, MaxRangeProofSize)
 	proofLen := uint64(5134)
 
@@ -71,24 +118,30 @@ func RangeProofSign(
 		cExtraCmtLen = len(extraCommit)
 	}
 
-	if 1 != C.secp256k1_rangeproof_sign(
-		context.ctx,
-		cBuf(proof),
-		(*C.size_t)(unsafe.Pointer(&proofLen)),
-		C.uint64_t(minValue),
-		commit.com,
-		cBuf(blindingFactor[:]),
-		cBuf(nonce[:]),
-		C.int(exp),
-		C.int(minBits),
-		C.uint64_t(value),
-		cMsg,
-		C.size_t(cMsgLen),
-		cExtraCmt,
-		C.size_t(cExtraCmtLen),
-		generator.gen,
-	) {
-		return nil, errors.New(ErrRangeProof)
+	result, err := call("secp256k1_rangeproof_sign", func() C.int {
+		return C.secp256k1_rangeproof_sign(
+			context.ctx,
+			cBuf(proof),
+			(*C.size_t)(unsafe.Pointer(&proofLen)),
+			C.uint64_t(minValue),
+			commit.com,
+			cBuf(blindingFactor[:]),
+			cBuf(nonce[:]),
+			C.int(exp),
+			C.int(minBits),
+			C.uint64_t(value),
+			cMsg,
+			C.size_t(cMsgLen),
+			cExtraCmt,
+			C.size_t(cExtraCmtLen),
+			generator.gen,
+		)
+	})
+	if err != nil {
+		return nil, newError(op, 0, err)
+	}
+	if 1 != result {
+		return nil, newError(op, result, ErrRangeProof)
 	}
 
 	return proof[:proofLen], nil
@@ -108,16 +161,29 @@ func RangeProofInfo(
 	context *Context,
 	proof []byte,
 ) (exp, mantissa int, minValue, maxValue uint64, err error) {
-	if 1 != C.secp256k1_rangeproof_info(
-		context.ctx,
-		(*C.int)(unsafe.Pointer(&exp)),
-		(*C.int)(unsafe.Pointer(&mantissa)),
-		(*C.uint64_t)(unsafe.Pointer(&minValue)),
-		(*C.uint64_t)(unsafe.Pointer(&maxValue)),
-		cBuf(proof),
-		C.size_t(len(proof)),
-	) {
-		err = errors.New(ErrRangeProofInfo)
+	if err = context.check(); err != nil {
+		err = newError("RangeProofInfo", 0, err)
+		return
+	}
+	defer runtime.KeepAlive(context)
+
+	result, err := call("secp256k1_rangeproof_info", func() C.int {
+		return C.secp256k1_rangeproof_info(
+			context.ctx,
+			(*C.int)(unsafe.Pointer(&exp)),
+			(*C.int)(unsafe.Pointer(&mantissa)),
+			(*C.uint64_t)(unsafe.Pointer(&minValue)),
+			(*C.uint64_t)(unsafe.Pointer(&maxValue)),
+			cBuf(proof),
+			C.size_t(len(proof)),
+		)
+	})
+	if err != nil {
+		err = newError("RangeProofInfo", 0, err)
+		return
+	}
+	if 1 != result {
+		err = newError("RangeProofInfo", result, ErrRangeProofInfo)
 		return
 	}
 
@@ -134,7 +200,13 @@ func RangeProofInfo(
 //       	 	 gen: additional generator 'h'
 // 	 Out:  	 min_value: pointer to a unsigned int64 which will be updated with the minimum value that commit could have. (cannot be NULL)
 //       	 	 max_value: pointer to a unsigned int64 which will be updated with the maximum value that commit could have. (cannot be NULL)
+// It panics with a *CallbackError if the arguments are not valid.
 func RangeProofVerify(context *Context, proof []byte, commit *Commitment, extraCommit []byte, generator *Generator) (bool, int, int) {
+	if context.check() != nil {
+		return false, 0, 0
+	}
+	defer runtime.KeepAlive(context)
+
 	var cExtraCmt *C.uchar
 	cExtraCmtLen := 0
 	if extraCommit != nil && len(extraCommit) > 0 {
@@ -145,17 +217,20 @@ func RangeProofVerify(context *Context, proof []byte, commit *Commitment, extraC
 	minValue := 0
 	maxValue := 0
 
-	if 1 != C.secp256k1_rangeproof_verify(
-		context.ctx,
-		(*C.uint64_t)(unsafe.Pointer(&minValue)),
-		(*C.uint64_t)(unsafe.Pointer(&maxValue)),
-		commit.com,
-		cBuf(proof),
-		C.size_t(len(proof)),
-		cExtraCmt,
-		C.size_t(cExtraCmtLen),
-		generator.gen,
-	) {
+	result := mustCall("secp256k1_rangeproof_verify", func() C.int {
+		return C.secp256k1_rangeproof_verify(
+			context.ctx,
+			(*C.uint64_t)(unsafe.Pointer(&minValue)),
+			(*C.uint64_t)(unsafe.Pointer(&maxValue)),
+			commit.com,
+			cBuf(proof),
+			C.size_t(len(proof)),
+			cExtraCmt,
+			C.size_t(cExtraCmtLen),
+			generator.gen,
+		)
+	})
+	if 1 != result {
 		return false, 0, 0
 	}
 
@@ -190,6 +265,59 @@ func RangeProofRewind(
 	message []byte,
 	err error,
 ) {
+	// the argument is a copy of the nonce of the caller
+	defer zero(nonce[:])
+	value, minValue, maxValue, message, err = rangeProofRewind("RangeProofRewind", context, commit, proof, (*SecretScalar)(&nonce), extraCommit, gen, (*SecretScalar)(&blindingFactor))
+	return
+}
+
+// RangeProofRewindSecret is RangeProofRewind with the nonce and the
+// recovered blinding factor held in SecretScalars, which are not copied.
+func RangeProofRewindSecret(
+	context *Context,
+	commit *Commitment,
+	proof []byte,
+	nonce *SecretScalar,
+	extraCommit []byte,
+	gen *Generator,
+) (
+	blindingFactor *SecretScalar,
+	value, minValue, maxValue uint64,
+	message []byte,
+	err error,
+) {
+	if nonce == nil {
+		err = newError("RangeProofRewindSecret", 0, ErrorSecretScalarNil)
+		return
+	}
+	blindingFactor = new(SecretScalar)
+	value, minValue, maxValue, message, err = rangeProofRewind("RangeProofRewindSecret", context, commit, proof, nonce, extraCommit, gen, blindingFactor)
+	if err != nil {
+		blindingFactor = nil
+	}
+	return
+}
+
+func rangeProofRewind(
+	op string,
+	context *Context,
+	commit *Commitment,
+	proof []byte,
+	nonce *SecretScalar,
+	extraCommit []byte,
+	gen *Generator,
+	blindingFactor *SecretScalar,
+) (
+	value, minValue, maxValue uint64,
+	message []byte,
+	err error,
+) {
+	if err = context.require(ContextBoth); err != nil {
+		err = newError(op, 0, err)
+		return
+	}
+	defer runtime.KeepAlive(context)
+
 	var cExtraCmt *C.uchar
 	cExtraCmtLen := 0
 	if extraCommit != nil && len(extraCommit) > 0 {
@@ -197,26 +325,37 @@ func RangeProofRewind(
 		cExtraCmtLen = len(extraCommit)
 	}
 
+	// the message may embed secrets such as an asset blinding factor
 	var msg [4096]byte
+	defer zero(msg[:])
 	msgLen := uint64(64)
 
-	if 1 != C.secp256k1_rangeproof_rewind(
-		context.ctx,
-		cBuf(blindingFactor[:]),
-		(*C.uint64_t)(unsafe.Pointer(&value)),
-		cBuf(msg[:]),
-		(*C.size_t)(unsafe.Pointer(&msgLen)),
-		cBuf(nonce[:]),
-		(*C.uint64_t)(unsafe.Pointer(&minValue)),
-		(*C.uint64_t)(unsafe.Pointer(&maxValue)),
-		commit.com,
-		cBuf(proof),
-		(C.size_t)(len(proof)),
-		cExtraCmt,
-		C.size_t(cExtraCmtLen),
-		gen.gen,
-	) {
-		err = errors.New(ErrRangeProofRewind)
+	result, err := call("secp256k1_rangeproof_rewind", func() C.int {
+		return C.secp256k1_rangeproof_rewind(
+			context.ctx,
+			cBuf(blindingFactor[:]),
+			(*C.uint64_t)(unsafe.Pointer(&value)),
+			cBuf(msg[:]),
+			(*C.size_t)(unsafe.Pointer(&msgLen)),
+			cBuf(nonce[:]),
+			(*C.uint64_t)(unsafe.Pointer(&minValue)),
+			(*C.uint64_t)(unsafe.Pointer(&maxValue)),
+			commit.com,
+			cBuf(proof),
+			(C.size_t)(len(proof)),
+			cExtraCmt,
+			C.size_t(cExtraCmtLen),
+			gen.gen,
+		)
+	})
+	if err != nil {
+		blindingFactor.Zero()
+		err = newError(op, 0, err)
+		return
+	}
+	if 1 != result {
+		blindingFactor.Zero()
+		err = newError(op, result, ErrRangeProofRewind)
 		return
 	}
 	message = make([]byte, msgLen)
@@ -0,0 +1,195 @@
+package secp256k1
+
+/*
+#include "include/secp256k1_recovery.h"
+#cgo CFLAGS: -I${SRCDIR}/secp256k1-zkp -I${SRCDIR}/secp256k1-zkp/src
+*/
+import "C"
+
+import (
+	"errors"
+	"runtime"
+)
+
+const (
+	// Length of elements byte representations
+	LenCompactRecoverableSignature int = 65
+)
+
+var (
+	ErrorRecoverableSignatureSize = errors.New("recoverable signature must be exactly 65 bytes")
+	ErrorRecoveryID               = errors.New("recovery id must be between 0 and 3")
+	ErrorPublicKeyRecover         = errors.New("unable to recover public key")
+)
+
+// RecoverableSignature wraps a *secp256k1_ecdsa_recoverable_signature, an
+// opaque 65-byte representation of an ECDSA signature together with the
+// recovery id needed to recover the public key that produced it.
+type RecoverableSignature struct {
+	sig *C.secp256k1_ecdsa_recoverable_signature
+}
+
+func newRecoverableSignature() *RecoverableSignature {
+	return &RecoverableSignature{
+		sig: &C.secp256k1_ecdsa_recoverable_signature{},
+	}
+}
+
+// EcdsaRecoverableSignatureParseCompact parses a recoverable signature in
+// compact (65 bytes) format, that is the 64-byte compact signature followed
+// by the recovery id (0, 1, 2 or 3). The return code is 1 when the signature
+// could be parsed, 0 otherwise.
+func EcdsaRecoverableSignatureParseCompact(ctx *Context, input65 []byte) (int, *RecoverableSignature, error) {
+	if len(input65) != LenCompactRecoverableSignature {
+		return 0, nil, newError("EcdsaRecoverableSignatureParseCompact", 0, ErrorRecoverableSignatureSize)
+	}
+	recid := int(input65[LenCompactSignature])
+	if recid > 3 {
+		return 0, nil, newError("EcdsaRecoverableSignatureParseCompact", 0, ErrorRecoveryID)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdsaRecoverableSignatureParseCompact", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newRecoverableSignature()
+	result, err := call("secp256k1_ecdsa_recoverable_signature_parse_compact", func() C.int {
+		return C.secp256k1_ecdsa_recoverable_signature_parse_compact(ctx.ctx, sig.sig, cBuf(input65), C.int(recid))
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaRecoverableSignatureParseCompact", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("EcdsaRecoverableSignatureParseCompact", result, ErrorSignatureParse)
+	}
+	return result, sig, nil
+}
+
+// EcdsaRecoverableSignatureSerializeCompact serializes a recoverable
+// signature in compact (65 bytes) format, the 64-byte compact signature
+// followed by the recovery id. The return code is always 1.
+func EcdsaRecoverableSignatureSerializeCompact(ctx *Context, sig *RecoverableSignature) (int, []byte, error) {
+	if sig == nil {
+		return 0, nil, newError("EcdsaRecoverableSignatureSerializeCompact", 0, ErrorSignatureNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdsaRecoverableSignatureSerializeCompact", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	output := make([]byte, LenCompactRecoverableSignature)
+	var recid C.int
+	result, err := call("secp256k1_ecdsa_recoverable_signature_serialize_compact", func() C.int {
+		return C.secp256k1_ecdsa_recoverable_signature_serialize_compact(ctx.ctx, cBuf(output), &recid, sig.sig)
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaRecoverableSignatureSerializeCompact", 0, err)
+	}
+	output[LenCompactSignature] = byte(recid)
+	return result, output, nil
+}
+
+// EcdsaRecoverableSignatureConvert converts a recoverable signature into a
+// normal signature, that can be verified with EcdsaVerify. The return code
+// is always 1.
+func EcdsaRecoverableSignatureConvert(ctx *Context, sig *RecoverableSignature) (int, *Signature, error) {
+	if sig == nil {
+		return 0, nil, newError("EcdsaRecoverableSignatureConvert", 0, ErrorSignatureNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("EcdsaRecoverableSignatureConvert", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	out := newSignature()
+	result, err := call("secp256k1_ecdsa_recoverable_signature_convert", func() C.int {
+		return C.secp256k1_ecdsa_recoverable_signature_convert(ctx.ctx, out.sig, sig.sig)
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaRecoverableSignatureConvert", 0, err)
+	}
+	return result, out, nil
+}
+
+// EcdsaSignRecoverable creates a recoverable ECDSA signature of the 32-byte
+// message hash with the given secret key. As with EcdsaSign the nonce is
+// derived following RFC6979 and the signature is in lower-S form. The context
+// must be initialized for signing. The return code is 1 if the signature was
+// created, 0 if the nonce generation failed or the secret key was invalid.
+func EcdsaSignRecoverable(ctx *Context, msg32 []byte, seckey []byte) (int, *RecoverableSignature, error) {
+	if len(msg32) != LenMessageHash {
+		return 0, nil, newError("EcdsaSignRecoverable", 0, ErrorMessageHashSize)
+	}
+	if len(seckey) != LenPrivateKey {
+		return 0, nil, newError("EcdsaSignRecoverable", 0, ErrorPrivateKeySize)
+	}
+
+	if err := ctx.require(ContextSign); err != nil {
+		return 0, nil, newError("EcdsaSignRecoverable", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newRecoverableSignature()
+	result, err := call("secp256k1_ecdsa_sign_recoverable", func() C.int {
+		return C.secp256k1_ecdsa_sign_recoverable(ctx.ctx, sig.sig, cBuf(msg32), cBuf(seckey), nil, nil)
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaSignRecoverable", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("EcdsaSignRecoverable", result, ErrorSignatureCreate)
+	}
+	return result, sig, nil
+}
+
+// EcdsaRecover recovers the public key that produced a recoverable signature
+// of the 32-byte message hash. The context must be initialized for
+// verification. The return code is 1 if the public key was recovered, 0
+// otherwise, in which case an error is returned.
+func EcdsaRecover(ctx *Context, sig *RecoverableSignature, msg32 []byte) (int, *PublicKey, error) {
+	if len(msg32) != LenMessageHash {
+		return 0, nil, newError("EcdsaRecover", 0, ErrorMessageHashSize)
+	}
+	if sig == nil {
+		return 0, nil, newError("EcdsaRecover", 0, ErrorSignatureNil)
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, nil, newError("EcdsaRecover", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	pk := newPublicKey()
+	result, err := call("secp256k1_ecdsa_recover", func() C.int {
+		return C.secp256k1_ecdsa_recover(ctx.ctx, pk.pk, sig.sig, cBuf(msg32))
+	})
+	if err != nil {
+		return 0, nil, newError("EcdsaRecover", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("EcdsaRecover", result, ErrorPublicKeyRecover)
+	}
+	return result, pk, nil
+}
+
+// SerializeCompact returns the signature in compact (65 bytes) format
+func (sig *RecoverableSignature) SerializeCompact() []byte {
+	_, bytes, _ := EcdsaRecoverableSignatureSerializeCompact(SharedContext(ContextNone), sig)
+	return bytes
+}
+
+// RecoveryID returns the recovery id of the signature, -1 if the signature
+// is nil
+func (sig *RecoverableSignature) RecoveryID() int {
+	if sig == nil {
+		return -1
+	}
+	return int(sig.SerializeCompact()[LenCompactSignature])
+}
+
+// ToSignature converts the recoverable signature into a normal signature
+func (sig *RecoverableSignature) ToSignature() *Signature {
+	_, out, _ := EcdsaRecoverableSignatureConvert(SharedContext(ContextNone), sig)
+	return out
+}
@@ -0,0 +1,92 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEcdsaSignRecoverableAndRecover(t *testing.T) {
+	file, err := ioutil.ReadFile("testdata/recovery.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testVectorType struct {
+		PrivKey            string `json:"privKey"`
+		Msg                string `json:"msg"`
+		PubKey             string `json:"pubKey"`
+		PubKeyUncompressed string `json:"pubKeyUncompressed"`
+		Signature          string `json:"signature"`
+	}
+	type testType struct {
+		Vectors []testVectorType `json:"recoverable"`
+	}
+
+	var test testType
+	json.Unmarshal(file, &test)
+
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	for _, v := range test.Vectors {
+		privKey, _ := hex.DecodeString(v.PrivKey)
+		msg, _ := hex.DecodeString(v.Msg)
+		signature, _ := hex.DecodeString(v.Signature)
+
+		_, sig, err := EcdsaSignRecoverable(ctx, msg, privKey)
+		assert.NoError(t, err)
+		assert.Equal(t, v.Signature, hex.EncodeToString(sig.SerializeCompact()))
+		assert.Equal(t, int(signature[64]), sig.RecoveryID())
+
+		_, parsed, err := EcdsaRecoverableSignatureParseCompact(ctx, signature)
+		assert.NoError(t, err)
+
+		_, pk, err := EcdsaRecover(ctx, parsed, msg)
+		assert.NoError(t, err)
+		_, compressed, _ := EcPubkeySerialize(ctx, pk, EcCompressed)
+		assert.Equal(t, v.PubKey, hex.EncodeToString(compressed))
+		_, uncompressed, _ := EcPubkeySerialize(ctx, pk, EcUncompressed)
+		assert.Equal(t, v.PubKeyUncompressed, hex.EncodeToString(uncompressed))
+
+		result, err := EcdsaVerify(ctx, parsed.ToSignature(), msg, pk)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+		assert.Equal(t, signature[:64], parsed.ToSignature().SerializeCompact())
+
+		// a different recovery id recovers a different key, or none at all
+		signature[64] ^= 1
+		_, other, _ := EcdsaRecoverableSignatureParseCompact(ctx, signature)
+		_, wrong, err := EcdsaRecover(ctx, other, msg)
+		if err == nil {
+			_, wrongBytes, _ := EcPubkeySerialize(ctx, wrong, EcCompressed)
+			assert.NotEqual(t, v.PubKey, hex.EncodeToString(wrongBytes))
+		} else {
+			assert.True(t, errors.Is(err, ErrorPublicKeyRecover))
+		}
+	}
+}
+
+func TestEcdsaRecoverableSignatureParseCompact(t *testing.T) {
+	ctx, _ := ContextCreate(ContextNone)
+	defer ContextDestroy(ctx)
+
+	_, _, err := EcdsaRecoverableSignatureParseCompact(ctx, make([]byte, 64))
+	assert.True(t, errors.Is(err, ErrorRecoverableSignatureSize))
+
+	input := make([]byte, 65)
+	input[31] = 1
+	input[63] = 1
+	input[64] = 4
+	_, _, err = EcdsaRecoverableSignatureParseCompact(ctx, input)
+	assert.True(t, errors.Is(err, ErrorRecoveryID))
+
+	input[64] = 3
+	_, sig, err := EcdsaRecoverableSignatureParseCompact(ctx, input)
+	assert.NoError(t, err)
+	assert.Equal(t, input, sig.SerializeCompact())
+}
@@ -0,0 +1,487 @@
+package secp256k1
+
+/*
+#include <stdlib.h>
+#include "include/secp256k1_schnorrsig.h"
+#cgo CFLAGS: -I${SRCDIR}/secp256k1-zkp -I${SRCDIR}/secp256k1-zkp/src
+static const secp256k1_schnorrsig** makeSchnorrsigArray(int size) { return !size ? NULL : calloc(sizeof(secp256k1_schnorrsig*), size); }
+static void setSchnorrsigArray(const secp256k1_schnorrsig** a, secp256k1_schnorrsig* v, int i) { if (a) a[i] = v; }
+static void freeSchnorrsigArray(const secp256k1_schnorrsig** a) { if (a) free(a); }
+static const unsigned char** makeMessagesArray(int size) { return !size ? NULL : calloc(sizeof(unsigned char*), size); }
+static void setMessagesArray(const unsigned char** a, unsigned char* v, int i) { if (a) a[i] = v; }
+static void freeMessagesArray(const unsigned char** a) { if (a) free(a); }
+static const secp256k1_pubkey** makeConstPubkeyArray(int size) { return !size ? NULL : calloc(sizeof(secp256k1_pubkey*), size); }
+static void setConstPubkeyArray(const secp256k1_pubkey** a, secp256k1_pubkey* v, int i) { if (a) a[i] = v; }
+static void freeConstPubkeyArray(const secp256k1_pubkey** a) { if (a) free(a); }
+*/
+import "C"
+
+import (
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"runtime"
+)
+
+const (
+	// Length of elements byte representations
+	LenSchnorrSignature int = 64
+
+	// DefaultScratchSpaceSize is the size of the scratch space allocated by
+	// SchnorrVerifyBatch when none is provided
+	DefaultScratchSpaceSize = 1 << 22
+)
+
+var (
+	ErrorSchnorrSignatureSize = errors.New("schnorr signature must be exactly 64 bytes")
+	ErrorSchnorrSignatureNil  = errors.New("schnorr signature is nil")
+	ErrorPublicKeyNil         = errors.New("public key is nil")
+	ErrorSignatureCount       = errors.New("number of signatures, messages and public keys differ")
+	ErrorAuxRandSize          = errors.New("auxiliary random data must be exactly 32 bytes")
+)
+
+var (
+	// curveP is the order of the field of the secp256k1 curve
+	curveP, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC2F", 16)
+	// curveN is the order of the group of the secp256k1 curve
+	curveN, _ = new(big.Int).SetString("FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141", 16)
+	// curveOrder is the 32-byte big endian representation of curveN
+	curveOrder = [32]byte{
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe,
+		0xba, 0xae, 0xdc, 0xe6, 0xaf, 0x48, 0xa0, 0x3b,
+		0xbf, 0xd2, 0x5e, 0x8c, 0xd0, 0x36, 0x41, 0x41,
+	}
+	// generator is the compressed serialization of the generator point G
+	generator = []byte{
+		0x02, 0x79, 0xbe, 0x66, 0x7e, 0xf9, 0xdc, 0xbb, 0xac, 0x55, 0xa0, 0x62,
+		0x95, 0xce, 0x87, 0x0b, 0x07, 0x02, 0x9b, 0xfc, 0xdb, 0x2d, 0xce, 0x28,
+		0xd9, 0x59, 0xf2, 0x81, 0x5b, 0x16, 0xf8, 0x17, 0x98,
+	}
+)
+
+// SchnorrSignature wraps a *secp256k1_schnorrsig, the 64-byte representation
+// of a Schnorr signature: the 32-byte X coordinate of the nonce point R
+// followed by the 32-byte big endian scalar s. It holds both the signatures
+// of the bip-schnorr draft implemented by the schnorrsig and musig modules
+// of the library, checked with SchnorrVerifyLegacy, and BIP340 signatures,
+// checked with SchnorrVerify.
+type SchnorrSignature struct {
+	sig *C.secp256k1_schnorrsig
+}
+
+func newSchnorrSignature() *SchnorrSignature {
+	return &SchnorrSignature{
+		sig: &C.secp256k1_schnorrsig{},
+	}
+}
+
+// SchnorrSignatureParse parses a Schnorr signature. The signature is the
+// 32-byte x coordinate of the nonce point R followed by the 32-byte big
+// endian scalar s. The return code is always 1, since an invalid signature
+// can only be detected when verifying it.
+func SchnorrSignatureParse(ctx *Context, input64 []byte) (int, *SchnorrSignature, error) {
+	if len(input64) != LenSchnorrSignature {
+		return 0, nil, newError("SchnorrSignatureParse", 0, ErrorSchnorrSignatureSize)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("SchnorrSignatureParse", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newSchnorrSignature()
+	result, err := call("secp256k1_schnorrsig_parse", func() C.int {
+		return C.secp256k1_schnorrsig_parse(ctx.ctx, sig.sig, cBuf(input64))
+	})
+	if err != nil {
+		return 0, nil, newError("SchnorrSignatureParse", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("SchnorrSignatureParse", result, ErrorSignatureParse)
+	}
+	return result, sig, nil
+}
+
+// SchnorrSignatureSerialize serializes a Schnorr signature into 64 bytes.
+// The return code is always 1.
+func SchnorrSignatureSerialize(ctx *Context, sig *SchnorrSignature) (int, []byte, error) {
+	if sig == nil {
+		return 0, nil, newError("SchnorrSignatureSerialize", 0, ErrorSchnorrSignatureNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("SchnorrSignatureSerialize", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	output := make([]byte, LenSchnorrSignature)
+	result, err := call("secp256k1_schnorrsig_serialize", func() C.int {
+		return C.secp256k1_schnorrsig_serialize(ctx.ctx, cBuf(output), sig.sig)
+	})
+	if err != nil {
+		return 0, nil, newError("SchnorrSignatureSerialize", 0, err)
+	}
+	return result, output, nil
+}
+
+// SchnorrSignLegacy creates a Schnorr signature of the 32-byte message hash
+// with the given secret key, as specified by the bip-schnorr draft that
+// predates BIP340 and is implemented by the schnorrsig module of the library.
+// It was named SchnorrSign before that name was given to BIP340 signatures:
+// for the same key and message both produce different signatures, which
+// only verify with the matching function. The nonce is derived
+// deterministically from the key and the message. The context must be
+// initialized for signing. The return code is 1 if the signature was
+// created, 0 if the secret key was invalid.
+func SchnorrSignLegacy(ctx *Context, msg32 []byte, seckey []byte) (int, *SchnorrSignature, error) {
+	if len(msg32) != LenMessageHash {
+		return 0, nil, newError("SchnorrSignLegacy", 0, ErrorMessageHashSize)
+	}
+	if len(seckey) != LenPrivateKey {
+		return 0, nil, newError("SchnorrSignLegacy", 0, ErrorPrivateKeySize)
+	}
+
+	if err := ctx.require(ContextSign); err != nil {
+		return 0, nil, newError("SchnorrSignLegacy", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newSchnorrSignature()
+	result, err := call("secp256k1_schnorrsig_sign", func() C.int {
+		return C.secp256k1_schnorrsig_sign(ctx.ctx, sig.sig, nil, cBuf(msg32), cBuf(seckey), nil, nil)
+	})
+	if err != nil {
+		return 0, nil, newError("SchnorrSignLegacy", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("SchnorrSignLegacy", result, ErrorSignatureCreate)
+	}
+	return result, sig, nil
+}
+
+// SchnorrVerifyLegacy verifies a Schnorr signature of the 32-byte message
+// hash with the given public key, as specified by the bip-schnorr draft that
+// predates BIP340. It was named SchnorrVerify before that name was given to
+// BIP340 signatures, and it is the one to use for the signatures of
+// SchnorrSignLegacy and of MuSig sessions. The context must be initialized
+// for verification. The return code is 1 for a correct signature, 0 for an
+// incorrect signature, in which case no error is returned.
+func SchnorrVerifyLegacy(ctx *Context, sig *SchnorrSignature, msg32 []byte, publicKey *PublicKey) (int, error) {
+	if len(msg32) != LenMessageHash {
+		return 0, newError("SchnorrVerifyLegacy", 0, ErrorMessageHashSize)
+	}
+	if sig == nil {
+		return 0, newError("SchnorrVerifyLegacy", 0, ErrorSchnorrSignatureNil)
+	}
+	if publicKey == nil {
+		return 0, newError("SchnorrVerifyLegacy", 0, ErrorPublicKeyNil)
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, newError("SchnorrVerifyLegacy", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_schnorrsig_verify", func() C.int {
+		return C.secp256k1_schnorrsig_verify(ctx.ctx, sig.sig, cBuf(msg32), publicKey.pk)
+	})
+	if err != nil {
+		return 0, newError("SchnorrVerifyLegacy", 0, err)
+	}
+	return result, nil
+}
+
+// SchnorrVerifyBatch verifies a set of Schnorr signatures of the bip-schnorr
+// draft at once, which is considerably faster than verifying them one by one
+// with SchnorrVerifyLegacy. BIP340 signatures are not supported. The i-th
+// signature is verified against the i-th message hash and public key. The return code is
+// 1 if all the signatures are correct, 0 if at least one of them is not, in
+// which case no error is returned; the offending signature can then be found
+// with SchnorrVerifyLegacy. The return code is also 1 for an empty set.
+// The context must be initialized for verification. If scratch is nil a
+// scratch space of DefaultScratchSpaceSize bytes is allocated for the call,
+// otherwise the given one is used, and a larger space speeds up the
+// verification of large batches.
+func SchnorrVerifyBatch(
+	ctx *Context,
+	scratch *ScratchSpace,
+	sigs []*SchnorrSignature,
+	msgs [][]byte,
+	publicKeys []*PublicKey,
+) (int, error) {
+	n := len(sigs)
+	if len(msgs) != n || len(publicKeys) != n {
+		return 0, newError("SchnorrVerifyBatch", 0, ErrorSignatureCount)
+	}
+	for i := 0; i < n; i++ {
+		if sigs[i] == nil {
+			return 0, newIndexError("SchnorrVerifyBatch", i, ErrorSchnorrSignatureNil)
+		}
+		if len(msgs[i]) != LenMessageHash {
+			return 0, newIndexError("SchnorrVerifyBatch", i, ErrorMessageHashSize)
+		}
+		if publicKeys[i] == nil {
+			return 0, newIndexError("SchnorrVerifyBatch", i, ErrorPublicKeyNil)
+		}
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, newError("SchnorrVerifyBatch", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	if scratch == nil {
+		var err error
+		scratch, err = ScratchSpaceCreate(ctx, DefaultScratchSpaceSize)
+		if err != nil {
+			return 0, err
+		}
+		defer scratch.Close()
+	}
+	if err := scratch.check(); err != nil {
+		return 0, newError("SchnorrVerifyBatch", 0, err)
+	}
+	defer runtime.KeepAlive(scratch)
+
+	sigArray := C.makeSchnorrsigArray(C.int(n))
+	defer C.freeSchnorrsigArray(sigArray)
+	msgArray := C.makeMessagesArray(C.int(n))
+	defer C.freeMessagesArray(msgArray)
+	pkArray := C.makeConstPubkeyArray(C.int(n))
+	defer C.freeConstPubkeyArray(pkArray)
+	for i := 0; i < n; i++ {
+		C.setSchnorrsigArray(sigArray, sigs[i].sig, C.int(i))
+		C.setMessagesArray(msgArray, cBuf(msgs[i]), C.int(i))
+		C.setConstPubkeyArray(pkArray, publicKeys[i].pk, C.int(i))
+	}
+
+	result, err := call("secp256k1_schnorrsig_verify_batch", func() C.int {
+		return C.secp256k1_schnorrsig_verify_batch(
+			ctx.ctx,
+			scratch.scratch,
+			sigArray,
+			msgArray,
+			pkArray,
+			C.size_t(n))
+	})
+	runtime.KeepAlive(sigs)
+	runtime.KeepAlive(msgs)
+	runtime.KeepAlive(publicKeys)
+	if err != nil {
+		return 0, newError("SchnorrVerifyBatch", 0, err)
+	}
+	return result, nil
+}
+
+// Serialize returns the signature serialized into 64 bytes
+func (sig *SchnorrSignature) Serialize() []byte {
+	_, bytes, _ := SchnorrSignatureSerialize(SharedContext(ContextNone), sig)
+	return bytes
+}
+
+// TaggedHash returns the BIP340 tagged hash of the concatenation of msgs,
+// that is SHA256(SHA256(tag) || SHA256(tag) || msgs...).
+func TaggedHash(tag string, msgs ...[]byte) []byte {
+	tagHash := sha256.Sum256([]byte(tag))
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+	return h.Sum(nil)
+}
+
+// scalarBytes returns the 32-byte big endian representation of x, which must
+// be lower than the order of the group. The arithmetic of big.Int is not
+// constant time, it must only be used for public values.
+func scalarBytes(x *big.Int) []byte {
+	out := make([]byte, LenPrivateKey)
+	b := x.Bytes()
+	copy(out[LenPrivateKey-len(b):], b)
+	return out
+}
+
+// hashToScalar returns the tagged hash of msgs reduced modulo the order of
+// the group, as a 32-byte big endian scalar. The reduction runs in constant
+// time, so that it can be used for secret nonces.
+func hashToScalar(tag string, msgs ...[]byte) []byte {
+	h := TaggedHash(tag, msgs...)
+	reduceScalar(h)
+	return h
+}
+
+// reduceScalar reduces the 32-byte big endian integer b modulo the order of
+// the group, in place and in constant time. Since the order is greater than
+// 2^255 a single conditional subtraction is enough.
+func reduceScalar(b []byte) {
+	var diff [32]byte
+	defer zero(diff[:])
+
+	borrow := uint32(0)
+	for i := len(b) - 1; i >= 0; i-- {
+		v := uint32(b[i]) - uint32(curveOrder[i]) - borrow
+		diff[i] = byte(v)
+		borrow = (v >> 8) & 1
+	}
+	// keep b - n unless the subtraction borrowed, that is unless b < n
+	mask := byte(borrow) - 1
+	for i := range b {
+		b[i] = b[i]&^mask | diff[i]&mask
+	}
+}
+
+// SchnorrSign creates a BIP340 Schnorr signature of msg, which can be of any
+// length although it is usually a 32-byte hash, with the given secret key.
+// The nonce is derived from the key, the message and auxRand32, 32 bytes of
+// fresh randomness that protect against side channel attacks; if nil, 32
+// zero bytes are used as BIP340 allows. The context must be initialized for
+// signing. The return code is 1 if the signature was created, 0 if the
+// secret key was invalid. The signatures of the bip-schnorr draft are
+// created with SchnorrSignLegacy instead.
+func SchnorrSign(ctx *Context, msg []byte, seckey []byte, auxRand32 []byte) (int, *SchnorrSignature, error) {
+	if len(seckey) != LenPrivateKey {
+		return 0, nil, newError("SchnorrSign", 0, ErrorPrivateKeySize)
+	}
+	if auxRand32 == nil {
+		auxRand32 = make([]byte, 32)
+	}
+	if len(auxRand32) != 32 {
+		return 0, nil, newError("SchnorrSign", 0, ErrorAuxRandSize)
+	}
+
+	if err := ctx.require(ContextSign); err != nil {
+		return 0, nil, newError("SchnorrSign", 0, err)
+	}
+
+	result, pk, err := EcPubkeyCreate(ctx, seckey)
+	if err != nil {
+		return result, nil, err
+	}
+	_, xonly, parity, err := XOnlyPubkeyFromPubkey(ctx, pk)
+	if err != nil {
+		return 0, nil, err
+	}
+	px := xonly.Serialize()
+
+	d := make([]byte, LenPrivateKey)
+	defer zero(d)
+	copy(d, seckey)
+	if parity == 1 {
+		if _, err := EcPrivKeyNegate(ctx, d); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	t := TaggedHash("BIP0340/aux", auxRand32)
+	defer zero(t)
+	for i := range t {
+		t[i] ^= d[i]
+	}
+	k := hashToScalar("BIP0340/nonce", t, px, msg)
+	defer zero(k)
+	result, r, err := EcPubkeyCreate(ctx, k)
+	if err != nil {
+		return result, nil, newError("SchnorrSign", result, ErrorSignatureCreate)
+	}
+	_, rxonly, parity, err := XOnlyPubkeyFromPubkey(ctx, r)
+	if err != nil {
+		return 0, nil, err
+	}
+	if parity == 1 {
+		if _, err := EcPrivKeyNegate(ctx, k); err != nil {
+			return 0, nil, err
+		}
+	}
+	rx := rxonly.Serialize()
+
+	// s = k + e*d, the challenge e is zero with negligible probability
+	e := hashToScalar("BIP0340/challenge", rx, px, msg)
+	if _, err := EcPrivKeyTweakMul(ctx, d, e); err != nil {
+		return 0, nil, newError("SchnorrSign", 0, ErrorSignatureCreate)
+	}
+	if _, err := EcPrivKeyTweakAdd(ctx, d, k); err != nil {
+		return 0, nil, newError("SchnorrSign", 0, ErrorSignatureCreate)
+	}
+
+	sig64 := make([]byte, LenSchnorrSignature)
+	copy(sig64, rx)
+	copy(sig64[32:], d)
+	_, sig, err := SchnorrSignatureParse(ctx, sig64)
+	if err != nil {
+		return 0, nil, err
+	}
+	return 1, sig, nil
+}
+
+// SchnorrVerify verifies a BIP340 Schnorr signature of msg with the given
+// x-only public key. The signatures of the bip-schnorr draft, including the
+// ones of MuSig sessions, are verified with SchnorrVerifyLegacy instead. The context must be initialized for
+// verification. The return code is 1 for a correct signature, 0 for an
+// incorrect signature, in which case no error is returned.
+func SchnorrVerify(ctx *Context, sig *SchnorrSignature, msg []byte, publicKey *XOnlyPublicKey) (int, error) {
+	if sig == nil {
+		return 0, newError("SchnorrVerify", 0, ErrorSchnorrSignatureNil)
+	}
+	if publicKey == nil {
+		return 0, newError("SchnorrVerify", 0, ErrorPublicKeyNil)
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, newError("SchnorrVerify", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	_, sig64, err := SchnorrSignatureSerialize(ctx, sig)
+	if err != nil {
+		return 0, err
+	}
+
+	r := new(big.Int).SetBytes(sig64[:32])
+	s := new(big.Int).SetBytes(sig64[32:])
+	if r.Cmp(curveP) >= 0 || s.Cmp(curveN) >= 0 {
+		return 0, nil
+	}
+
+	// R = s*G - e*P, where either term vanishes if its scalar is zero
+	px := publicKey.Serialize()
+	e := new(big.Int).SetBytes(hashToScalar("BIP0340/challenge", sig64[:32], px, msg))
+	terms := make([]*PublicKey, 0, 2)
+	if s.Sign() != 0 {
+		_, g, err := EcPubkeyParse(ctx, generator)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := EcPubKeyTweakMul(ctx, g, sig64[32:]); err != nil {
+			return 0, err
+		}
+		terms = append(terms, g)
+	}
+	if e.Sign() != 0 {
+		eP := publicKey.PublicKey()
+		if _, err := EcPubKeyTweakMul(ctx, eP, scalarBytes(e.Sub(curveN, e))); err != nil {
+			return 0, err
+		}
+		terms = append(terms, eP)
+	}
+	if len(terms) == 0 {
+		return 0, nil
+	}
+	// the sum is the point at infinity if combining fails
+	_, R, err := EcPubKeyCombine(ctx, terms)
+	if err != nil {
+		if errors.Is(err, ErrorPublicKeyCombine) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	_, compressed, err := EcPubkeySerialize(ctx, R, EcCompressed)
+	if err != nil {
+		return 0, err
+	}
+	if compressed[0] != 0x02 || new(big.Int).SetBytes(compressed[1:]).Cmp(r) != 0 {
+		return 0, nil
+	}
+	return 1, nil
+}
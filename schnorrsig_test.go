@@ -0,0 +1,239 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schnorrsigTestVector struct {
+	PrivKey   string `json:"privKey"`
+	PubKey    string `json:"pubKey"`
+	Msg       string `json:"msg"`
+	Signature string `json:"signature"`
+}
+
+func loadSchnorrsigVectors(t *testing.T) []schnorrsigTestVector {
+	file, err := ioutil.ReadFile("testdata/schnorrsig.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests struct {
+		Vectors []schnorrsigTestVector `json:"sign"`
+	}
+	if err := json.Unmarshal(file, &tests); err != nil {
+		t.Fatal(err)
+	}
+	return tests.Vectors
+}
+
+func TestSchnorrSignAndVerifyLegacy(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	for _, v := range loadSchnorrsigVectors(t) {
+		privKey, _ := hex.DecodeString(v.PrivKey)
+		pubKey, _ := hex.DecodeString(v.PubKey)
+		msg, _ := hex.DecodeString(v.Msg)
+		signature, _ := hex.DecodeString(v.Signature)
+
+		_, sig, err := SchnorrSignLegacy(ctx, msg, privKey)
+		assert.NoError(t, err)
+		assert.Equal(t, v.Signature, hex.EncodeToString(sig.Serialize()))
+
+		_, parsed, err := SchnorrSignatureParse(ctx, signature)
+		assert.NoError(t, err)
+		_, pk, _ := EcPubkeyParse(ctx, pubKey)
+		result, err := SchnorrVerifyLegacy(ctx, parsed, msg, pk)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+
+		signature[63] ^= 1
+		_, parsed, _ = SchnorrSignatureParse(ctx, signature)
+		result, err = SchnorrVerifyLegacy(ctx, parsed, msg, pk)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, result)
+	}
+
+	_, _, err := SchnorrSignatureParse(ctx, make([]byte, 63))
+	assert.True(t, errors.Is(err, ErrorSchnorrSignatureSize))
+}
+
+func TestSchnorrVerifyBatch(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	const n = 200
+	sigs := make([]*SchnorrSignature, n)
+	msgs := make([][]byte, n)
+	pks := make([]*PublicKey, n)
+	for i := 0; i < n; i++ {
+		privKey := testingRand32()
+		msg := testingRand32()
+		_, sig, err := SchnorrSignLegacy(ctx, msg[:], privKey[:])
+		assert.NoError(t, err)
+		_, pk, _ := EcPubkeyCreate(ctx, privKey[:])
+		sigs[i], msgs[i], pks[i] = sig, msg[:], pk
+	}
+
+	result, err := SchnorrVerifyBatch(ctx, nil, sigs, msgs, pks)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+
+	scratch, err := ScratchSpaceCreate(ctx, 1<<16)
+	assert.NoError(t, err)
+	result, err = SchnorrVerifyBatch(ctx, scratch, sigs, msgs, pks)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+
+	result, err = SchnorrVerifyBatch(ctx, scratch, nil, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+
+	msgs[n/2][0] ^= 1
+	result, err = SchnorrVerifyBatch(ctx, scratch, sigs, msgs, pks)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, result)
+
+	_, err = SchnorrVerifyBatch(ctx, scratch, sigs, msgs[1:], pks)
+	assert.True(t, errors.Is(err, ErrorSignatureCount))
+
+	msgs[3] = msgs[3][:31]
+	_, err = SchnorrVerifyBatch(ctx, scratch, sigs, msgs, pks)
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 3, e.Index)
+	assert.True(t, errors.Is(err, ErrorMessageHashSize))
+
+	ScratchSpaceDestroy(scratch)
+	ScratchSpaceDestroy(scratch)
+	msgs[3] = append(msgs[3], 0)
+	_, err = SchnorrVerifyBatch(ctx, scratch, sigs, msgs, pks)
+	assert.True(t, errors.Is(err, ErrScratchSpaceDestroyed))
+}
+
+func TestSchnorrSignAndVerify(t *testing.T) {
+	file, err := ioutil.ReadFile("testdata/bip340.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testVectorType struct {
+		Index     int    `json:"index"`
+		SecretKey string `json:"secretKey"`
+		PublicKey string `json:"publicKey"`
+		AuxRand   string `json:"auxRand"`
+		Message   string `json:"message"`
+		Signature string `json:"signature"`
+		Result    bool   `json:"result"`
+		Comment   string `json:"comment"`
+	}
+	type testType struct {
+		Vectors []testVectorType `json:"bip340"`
+	}
+
+	var test testType
+	json.Unmarshal(file, &test)
+
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	for _, v := range test.Vectors {
+		publicKey, _ := hex.DecodeString(v.PublicKey)
+		msg, _ := hex.DecodeString(v.Message)
+		signature, _ := hex.DecodeString(v.Signature)
+
+		if v.SecretKey != "" {
+			secretKey, _ := hex.DecodeString(v.SecretKey)
+			auxRand, _ := hex.DecodeString(v.AuxRand)
+
+			_, pk, _ := EcPubkeyCreate(ctx, secretKey)
+			_, xonly, _, _ := XOnlyPubkeyFromPubkey(ctx, pk)
+			assert.Equal(t, v.PublicKey, hex.EncodeToString(xonly.Serialize()), v.Index)
+
+			_, sig, err := SchnorrSign(ctx, msg, secretKey, auxRand)
+			assert.NoError(t, err, v.Index)
+			assert.Equal(t, v.Signature, hex.EncodeToString(sig.Serialize()), v.Index)
+		}
+
+		_, xonly, err := XOnlyPubkeyParse(ctx, publicKey)
+		if err != nil {
+			assert.False(t, v.Result, v.Index)
+			continue
+		}
+		_, sig, _ := SchnorrSignatureParse(ctx, signature)
+		result, err := SchnorrVerify(ctx, sig, msg, xonly)
+		assert.NoError(t, err, v.Index)
+		assert.Equal(t, v.Result, result == 1, "%d: %s", v.Index, v.Comment)
+	}
+}
+
+func TestSchnorrSignInvalidInput(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	seckey := make([]byte, 32)
+	seckey[31] = 3
+	msg := make([]byte, 32)
+
+	// a nil auxiliary random data is the same as 32 zero bytes
+	_, sig, err := SchnorrSign(ctx, msg, seckey, nil)
+	assert.NoError(t, err)
+	_, expected, _ := SchnorrSign(ctx, msg, seckey, make([]byte, 32))
+	assert.Equal(t, expected.Serialize(), sig.Serialize())
+
+	_, _, err = SchnorrSign(ctx, msg, seckey, make([]byte, 31))
+	assert.True(t, errors.Is(err, ErrorAuxRandSize))
+
+	_, _, err = SchnorrSign(ctx, msg, make([]byte, 32), nil)
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+
+	verifyCtx, _ := ContextCreate(ContextVerify)
+	defer ContextDestroy(verifyCtx)
+	_, _, err = SchnorrSign(verifyCtx, msg, seckey, nil)
+	assert.True(t, errors.Is(err, ErrContextCapability))
+
+	_, err = SchnorrVerify(ctx, nil, msg, nil)
+	assert.True(t, errors.Is(err, ErrorSchnorrSignatureNil))
+	_, err = SchnorrVerify(ctx, sig, msg, nil)
+	assert.True(t, errors.Is(err, ErrorPublicKeyNil))
+}
+
+func TestTaggedHash(t *testing.T) {
+	hash := TaggedHash("BIP0340/challenge", []byte("abc"))
+	assert.Equal(t, "770a5b7e7c304bbcc3ea107343ff951dd404312ef418db0c3b94e2ebfbb50087", hex.EncodeToString(hash))
+	assert.Equal(t, hash, TaggedHash("BIP0340/challenge", []byte("a"), nil, []byte("bc")))
+	assert.Equal(t,
+		TaggedHash("TapLeaf", []byte{0xc0}, []byte{0x01, 0x51}),
+		TaggedHash("TapLeaf", []byte{0xc0, 0x01, 0x51}),
+	)
+	assert.NotEqual(t, hash, TaggedHash("BIP0340/nonce", []byte("abc")))
+}
+
+func TestReduceScalar(t *testing.T) {
+	one := big.NewInt(1)
+	values := []*big.Int{
+		big.NewInt(0),
+		new(big.Int).Sub(curveN, one),
+		new(big.Int).Set(curveN),
+		new(big.Int).Add(curveN, big.NewInt(5)),
+		new(big.Int).Sub(new(big.Int).Lsh(one, 256), one),
+	}
+	for i := 0; i < 64; i++ {
+		random := testingRand32()
+		values = append(values, new(big.Int).SetBytes(random[:]))
+	}
+
+	for _, v := range values {
+		b := make([]byte, 32)
+		copy(b[32-len(v.Bytes()):], v.Bytes())
+		reduceScalar(b)
+		expected := new(big.Int).Mod(v, curveN)
+		assert.Equal(t, scalarBytes(expected), b, v.Text(16))
+	}
+}
@@ -0,0 +1,16 @@
+package secp256k1
+
+// Opcodes of the scripts built and parsed by this package, as defined by
+// Bitcoin and shared by Elements
+const (
+	opPushData1   = 0x4c
+	opPushData2   = 0x4d
+	opPushData4   = 0x4e
+	op1           = 0x51
+	opReturn      = 0x6a
+	opDup         = 0x76
+	opEqual       = 0x87
+	opEqualVerify = 0x88
+	opHash160     = 0xa9
+	opCheckSig    = 0xac
+)
@@ -3,21 +3,66 @@ package secp256k1
 /*
 #define SECP256K1_API __attribute__ ((visibility ("hidden")))
 
+// Keep the NULL argument checks of the library, the compiler would otherwise
+// optimize them out because of the nonnull attributes of the public headers.
+#define SECP256K1_BUILD 1
+
 #define USE_BASIC_CONFIG 1
 #include "./secp256k1-zkp/src/basic-config.h"
 
 #define ENABLE_MODULE_ECDH 1
+#define ENABLE_MODULE_RECOVERY 1
 #define ENABLE_MODULE_GENERATOR 1
 #define ENABLE_MODULE_RANGEPROOF 1
 #define ENABLE_MODULE_SURJECTIONPROOF 1
+#define ENABLE_MODULE_SCHNORRSIG 1
+#define ENABLE_MODULE_MUSIG 1
+#define ENABLE_MODULE_WHITELIST 1
 
 #include "secp256k1-zkp/src/secp256k1.c"
+#include "secp256k1-zkp/contrib/lax_der_parsing.c"
+
+// The illegal and error callbacks record their message in thread-local
+// storage, so that the Go side can collect it right after the call into the
+// library, from the same OS thread, instead of letting the process abort.
+static __thread const char *callbackMessage = NULL;
+static __thread int callbackIllegal = 0;
+
+static void illegalCallback(const char *message, void *data) {
+    (void)data;
+    callbackMessage = message;
+    callbackIllegal = 1;
+}
 
-#cgo CFLAGS: -I${SRCDIR}/secp256k1-zkp -I${SRCDIR}/secp256k1-zkp/src
+static void errorCallback(const char *message, void *data) {
+    (void)data;
+    callbackMessage = message;
+    callbackIllegal = 0;
+}
+
+static void setCallbacks(secp256k1_context *ctx) {
+    secp256k1_context_set_illegal_callback(ctx, illegalCallback, NULL);
+    secp256k1_context_set_error_callback(ctx, errorCallback, NULL);
+}
+
+static const char *takeCallbackMessage(int *illegal) {
+    const char *message = callbackMessage;
+    *illegal = callbackIllegal;
+    callbackMessage = NULL;
+    callbackIllegal = 0;
+    return message;
+}
+
+#cgo CFLAGS: -I${SRCDIR}/secp256k1-zkp -I${SRCDIR}/secp256k1-zkp/src -I${SRCDIR}/secp256k1-zkp/include
 */
 import "C"
 import (
+	"crypto/rand"
+	"errors"
 	"fmt"
+	"io"
+	"runtime"
+	"sync"
 	"unsafe"
 )
 
@@ -32,12 +77,182 @@ const (
 	ContextBoth = ContextSign | ContextVerify
 )
 
-var ctxmap map[uint]*Context
+var (
+	// ErrInvalidInput is matched by errors.Is for every failure caused by
+	// the arguments given to a function, as opposed to ErrRetryable.
+	ErrInvalidInput = errors.New("invalid input")
+	// ErrRetryable is matched by errors.Is for the failures that happen with
+	// negligible probability for random inputs (e.g. a blinding factor or a
+	// nonce that overflows the group order) and that should be retried with
+	// fresh randomness.
+	ErrRetryable = errors.New("retry with a different nonce or blinding factor")
+
+	// ErrContextRandomize is returned when a context could not be randomized
+	ErrContextRandomize = errors.New("unable to randomize context")
+	// ErrContextDestroyed is returned when using a context that has been
+	// destroyed
+	ErrContextDestroyed = errors.New("context has been destroyed")
+	// ErrContextFlags is returned when creating a context with invalid flags
+	ErrContextFlags = errors.New("invalid context flags")
+	// ErrContextCapability is returned when a context lacks the capabilities,
+	// signing or verification, required by a function
+	ErrContextCapability = errors.New("context not initialized for this operation")
+	// ErrScratchSpaceCreate is returned when a scratch space could not be
+	// allocated
+	ErrScratchSpaceCreate = errors.New("unable to allocate scratch space")
+	// ErrScratchSpaceDestroyed is returned when using a scratch space that has
+	// been destroyed
+	ErrScratchSpaceDestroyed = errors.New("scratch space has been destroyed")
+)
+
+var (
+	ctxmap map[uint]*Context
+	ctxmu  sync.Mutex
+)
 
 // Context wraps a *secp256k1_context, required to use all functions.
 // It can be initialized for signing, verification, or both.
+// The underlying C object is released when the Context is garbage collected,
+// or earlier with Close or ContextDestroy.
 type Context struct {
-	ctx *C.secp256k1_context
+	ctx   *C.secp256k1_context
+	flags uint
+}
+
+// ScratchSpace wraps a *secp256k1_scratch_space, the memory used by the
+// functions that perform many multiplications at once, like batch
+// verification. A ScratchSpace must not be used by more than one goroutine at
+// a time. The underlying C object is released when the ScratchSpace is
+// garbage collected, or earlier with Close or ScratchSpaceDestroy.
+type ScratchSpace struct {
+	scratch *C.secp256k1_scratch_space
+	size    int
+}
+
+// CallbackError is returned when a call into libsecp256k1 is aborted by the
+// illegal argument callback (i.e. an argument violated the function
+// preconditions) or by the error callback (i.e. an internal consistency
+// check failed). Functions that do not return an error panic with a
+// *CallbackError instead.
+type CallbackError struct {
+	// Func is the name of the C function that failed
+	Func string
+	// Message is the message passed to the callback
+	Message string
+	// Illegal is true if the failure was reported by the illegal callback
+	Illegal bool
+}
+
+func (e *CallbackError) Error() string {
+	if e.Illegal {
+		return fmt.Sprintf("%s: illegal argument: %s", e.Func, e.Message)
+	}
+	return fmt.Sprintf("%s: internal consistency check failed: %s", e.Func, e.Message)
+}
+
+// Is reports a failure of the illegal callback as ErrInvalidInput.
+func (e *CallbackError) Is(target error) bool {
+	return target == ErrInvalidInput && e.Illegal
+}
+
+// Error is the error returned by the functions of the package. It wraps one
+// of the exported sentinel errors, or a *CallbackError, that can be matched
+// with errors.Is and errors.As.
+type Error struct {
+	// Op is the name of the function that failed
+	Op string
+	// Index is the index of the offending element for the functions that
+	// accept a list of inputs, -1 otherwise
+	Index int
+	// Code is the return code of the underlying C function, 0 if the
+	// function was not called
+	Code int
+	// Err is the underlying error
+	Err error
+}
+
+func newError(op string, code int, err error) error {
+	return &Error{Op: op, Index: -1, Code: code, Err: err}
+}
+
+func newIndexError(op string, index int, err error) error {
+	return &Error{Op: op, Index: index, Err: err}
+}
+
+func (e *Error) Error() string {
+	if e.Index >= 0 {
+		return fmt.Sprintf("%s: input %d: %s", e.Op, e.Index, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Op, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrRetryable and ErrInvalidInput according to the kind of the
+// underlying error.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrRetryable:
+		return isRetryable(e.Err)
+	case ErrInvalidInput:
+		var cbErr *CallbackError
+		if errors.As(e.Err, &cbErr) {
+			return cbErr.Illegal
+		}
+		return !isRetryable(e.Err) && e.Err != ErrContextRandomize
+	}
+	return false
+}
+
+// isRetryable reports whether err is one of the sentinel errors matched by
+// ErrRetryable. It compares err with each of them rather than looking it up
+// in a map, which would panic on errors of an unhashable type, such as the
+// ones of a caller supplied io.Reader.
+func isRetryable(err error) bool {
+	switch err {
+	case ErrCommitmentCommit,
+		ErrCommitmentBlindSum,
+		ErrGeneratorGenerate,
+		ErrSurjectionProofAllocation,
+		ErrSurjectionProofInitialization,
+		ErrSurjectionProofGeneration:
+		return true
+	}
+	return false
+}
+
+// call runs fn, which must wrap a single call to the C function named name,
+// and returns a *CallbackError if the library reported a failure through one
+// of the callbacks while it ran. The goroutine is locked to its OS thread for
+// the duration of the call so that the thread-local message is read back from
+// the same thread that set it.
+func call(name string, fn func() C.int) (int, error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	result := int(fn())
+
+	var illegal C.int
+	if message := C.takeCallbackMessage(&illegal); message != nil {
+		return 0, &CallbackError{
+			Func:    name,
+			Message: C.GoString(message),
+			Illegal: illegal == 1,
+		}
+	}
+	return result, nil
+}
+
+// mustCall is like call but panics with the *CallbackError, for the functions
+// whose signature does not allow to return an error.
+func mustCall(name string, fn func() C.int) int {
+	result, err := call(name, fn)
+	if err != nil {
+		panic(err)
+	}
+	return result
 }
 
 func init() {
@@ -45,19 +260,62 @@ func init() {
 }
 
 func newContext() *Context {
-	var ctx *C.secp256k1_context
-	return &Context{ctx}
+	return &Context{}
+}
+
+// track registers a finalizer that destroys the underlying C context once
+// the object becomes unreachable.
+func (c *Context) track() *Context {
+	runtime.SetFinalizer(c, (*Context).Close)
+	return c
+}
+
+// check returns an error if the context is nil or has been destroyed.
+func (c *Context) check() error {
+	if c == nil || c.ctx == nil {
+		return ErrContextDestroyed
+	}
+	return nil
+}
+
+// require is like check but also returns an error if the context was not
+// created with all the given flags.
+func (c *Context) require(flags uint) error {
+	if err := c.check(); err != nil {
+		return err
+	}
+	if c.flags&flags != flags {
+		return ErrContextCapability
+	}
+	return nil
+}
+
+// Flags returns the flags the context was created with.
+func (c *Context) Flags() uint {
+	return c.flags
+}
+
+// Close destroys the underlying C context. It is safe to call it more than
+// once, while any later use of the context returns an error.
+func (c *Context) Close() error {
+	if c == nil || c.ctx == nil {
+		return nil
+	}
+	C.secp256k1_context_destroy(c.ctx)
+	c.ctx = nil
+	runtime.SetFinalizer(c, nil)
+	return nil
 }
 
 func cBuf(goSlice []byte) *C.uchar {
-	if goSlice == nil {
+	if len(goSlice) == 0 {
 		return nil
 	}
 	return (*C.uchar)(unsafe.Pointer(&goSlice[0]))
 }
 
 func u64Arr(a []uint64) *C.uint64_t {
-	if a == nil {
+	if len(a) == 0 {
 		return nil
 	}
 	return (*C.uint64_t)(unsafe.Pointer(&a[0]))
@@ -67,48 +325,167 @@ func goBytes(cSlice []C.uchar, size C.int) []byte {
 	return C.GoBytes(unsafe.Pointer(&cSlice[0]), size)
 }
 
+// zero overwrites b with zeros, to clear secret material once used.
+func zero(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
 // ContextCreate produces a new *Context, initialized with a bitmask of flags
 // depending on it's intended usage. The supported flags are currently
-// ContextSign and ContextVerify. Although expressed in the return type
-// signature, the function does not currently return an error.
+// ContextNone, ContextSign and ContextVerify; an error is returned for any
+// other value. The illegal and error callbacks of the context are set so that
+// misuses of the library are reported as a *CallbackError instead of
+// aborting the process.
 func ContextCreate(flags uint) (*Context, error) {
+	if flags&^ContextBoth != 0 || flags&ContextNone != ContextNone {
+		return nil, newError("ContextCreate", 0, ErrContextFlags)
+	}
+
 	context := newContext()
 	context.ctx = C.secp256k1_context_create(C.uint(flags))
-	return context, nil
+	context.flags = flags
+	C.setCallbacks(context.ctx)
+	return context.track(), nil
 }
 
-// ContextClone makes a copy of the provided *Context. The provided context
-// must not be NULL.
+// ContextClone makes a copy of the provided *Context. An error is returned
+// if the provided context has been destroyed.
 func ContextClone(ctx *Context) (*Context, error) {
+	if err := ctx.check(); err != nil {
+		return nil, newError("ContextClone", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
 	other := newContext()
-	other.ctx = C.secp256k1_context_clone(ctx.ctx)
-	return other, nil
+	_, err := call("secp256k1_context_clone", func() C.int {
+		other.ctx = C.secp256k1_context_clone(ctx.ctx)
+		return 1
+	})
+	if err != nil {
+		return nil, newError("ContextClone", 0, err)
+	}
+	other.flags = ctx.flags
+	return other.track(), nil
 }
 
-// ContextDestroy destroys the context. The provided context must not be NULL.
+// ContextDestroy destroys the context. It is equivalent to ctx.Close() and
+// it is safe to call it more than once.
 func ContextDestroy(ctx *Context) {
-	C.secp256k1_context_destroy(ctx.ctx)
+	ctx.Close()
 }
 
 // ContextRandomize accepts a [32]byte seed in order to update the context
-// randomization. NULL may be passed to reset to initial state. The context
-// pointer must not be null.
+// randomization. NULL may be passed to reset to initial state. The return
+// code is 0 if the context has been destroyed.
 func ContextRandomize(ctx *Context, seed32 [32]byte) int {
-	return int(C.secp256k1_context_randomize(ctx.ctx, cBuf(seed32[:])))
+	if ctx.check() != nil {
+		return 0
+	}
+	defer runtime.KeepAlive(ctx)
+
+	return mustCall("secp256k1_context_randomize", func() C.int {
+		return C.secp256k1_context_randomize(ctx.ctx, cBuf(seed32[:]))
+	})
 }
 
-// SharedContext returns a managed context
+// randomizeContext seeds the context randomization with 32 bytes read from
+// crypto/rand.
+func randomizeContext(ctx *Context) error {
+	var seed [32]byte
+	if _, err := io.ReadFull(rand.Reader, seed[:]); err != nil {
+		return err
+	}
+	if ContextRandomize(ctx, seed) != 1 {
+		return ErrContextRandomize
+	}
+	return nil
+}
+
+// SharedContext returns a managed context. It is safe to call from multiple
+// goroutines. The context is randomized once, when it is created, and must
+// not be destroyed or randomized again by the caller since it is shared by
+// the whole package. Use a ContextPool to get contexts that are
+// re-randomized during their lifetime.
 func SharedContext(flags uint) (context *Context) {
-	flags = flags & ContextBoth
+	flags = flags&ContextBoth | ContextNone
+
+	ctxmu.Lock()
+	defer ctxmu.Unlock()
+
 	context, exists := ctxmap[flags]
-	if !exists {
+	if !exists || context.check() != nil {
 		var err error
 		context, err = ContextCreate(flags)
 		if err != nil {
 			panic(fmt.Sprintf("error creating default context object (flags: %d, error: %s)", flags, err))
 		}
+		if err := randomizeContext(context); err != nil {
+			panic(fmt.Sprintf("error randomizing default context object (flags: %d, error: %s)", flags, err))
+		}
 		ctxmap[flags] = context
 	}
 
 	return
 }
+
+// ScratchSpaceCreate allocates a scratch space with size bytes of memory
+// available to the functions that use it.
+func ScratchSpaceCreate(ctx *Context, size int) (*ScratchSpace, error) {
+	if err := ctx.check(); err != nil {
+		return nil, newError("ScratchSpaceCreate", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	var scratch *C.secp256k1_scratch_space
+	_, err := call("secp256k1_scratch_space_create", func() C.int {
+		scratch = C.secp256k1_scratch_space_create(ctx.ctx, C.size_t(size))
+		return 1
+	})
+	if err != nil {
+		return nil, newError("ScratchSpaceCreate", 0, err)
+	}
+	if scratch == nil {
+		return nil, newError("ScratchSpaceCreate", 0, ErrScratchSpaceCreate)
+	}
+	space := &ScratchSpace{scratch: scratch, size: size}
+	runtime.SetFinalizer(space, (*ScratchSpace).Close)
+	return space, nil
+}
+
+// ScratchSpaceDestroy destroys the scratch space. It is equivalent to
+// scratch.Close() and it is safe to call it more than once.
+func ScratchSpaceDestroy(scratch *ScratchSpace) {
+	scratch.Close()
+}
+
+// check returns an error if the scratch space is nil or has been destroyed.
+func (s *ScratchSpace) check() error {
+	if s == nil || s.scratch == nil {
+		return ErrScratchSpaceDestroyed
+	}
+	return nil
+}
+
+// Size returns the number of bytes the scratch space was created with.
+func (s *ScratchSpace) Size() int {
+	return s.size
+}
+
+// Close releases the memory of the scratch space. It is safe to call it more
+// than once, while any later use of the scratch space returns an error.
+func (s *ScratchSpace) Close() error {
+	if s == nil || s.scratch == nil {
+		return nil
+	}
+	// the context is only used to report a corrupted scratch space
+	ctx := SharedContext(ContextNone)
+	_, err := call("secp256k1_scratch_space_destroy", func() C.int {
+		C.secp256k1_scratch_space_destroy(ctx.ctx, s.scratch)
+		return 1
+	})
+	s.scratch = nil
+	runtime.SetFinalizer(s, nil)
+	return err
+}
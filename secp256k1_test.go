@@ -2,6 +2,7 @@ package secp256k1
 
 import (
 	"crypto/rand"
+	"errors"
 	"io"
 	"testing"
 
@@ -30,6 +31,75 @@ func TestContextCreate(t *testing.T) {
 	ContextDestroy(ctx)
 }
 
+func TestContextClose(t *testing.T) {
+	ctx, err := ContextCreate(ContextBoth)
+	assert.NoError(t, err)
+
+	assert.NoError(t, ctx.Close())
+	assert.NoError(t, ctx.Close())
+	ContextDestroy(ctx)
+
+	_, err = ContextClone(ctx)
+	assert.Error(t, err)
+	assert.Equal(t, 0, ContextRandomize(ctx, testingRand32()))
+
+	blind := testingRand32()
+	_, _, err = EcPubkeyCreate(ctx, blind[:])
+	assert.Error(t, err)
+	_, err = Commit(ctx, blind[:], 10, &GeneratorH)
+	assert.Error(t, err)
+}
+
+func TestContextFlags(t *testing.T) {
+	_, err := ContextCreate(0)
+	assert.Error(t, err)
+	_, err = ContextCreate(ContextBoth | 1<<12)
+	assert.Error(t, err)
+
+	ctx, err := ContextCreate(ContextVerify)
+	assert.NoError(t, err)
+	defer ContextDestroy(ctx)
+	assert.Equal(t, ContextVerify, ctx.Flags())
+
+	clone, err := ContextClone(ctx)
+	assert.NoError(t, err)
+	defer ContextDestroy(clone)
+	assert.Equal(t, ContextVerify, clone.Flags())
+
+	blind := testingRand32()
+	commit, err := CommitmentFromString("09c6047f9441ed7d6d3045406e95c07cd85c778e4b8cef3ca7abac09b95c709ee5")
+	assert.NoError(t, err)
+	_, err = RangeProofSign(ctx, 0, commit, blind, blind, 0, 0, 10, nil, nil, &GeneratorH)
+	assert.True(t, errors.Is(err, ErrContextCapability))
+	_, err = Commit(ctx, blind[:], 10, &GeneratorH)
+	assert.True(t, errors.Is(err, ErrContextCapability))
+}
+
+func TestIllegalCallback(t *testing.T) {
+	ctx, err := ContextCreate(ContextBoth)
+	assert.NoError(t, err)
+	defer ContextDestroy(ctx)
+
+	// an empty commitment is now rejected by CommitmentParse before calling
+	// into C, so an uninitialized one is serialized instead
+	_, err = CommitmentSerialize(ctx, &Commitment{})
+	var cbErr *CallbackError
+	assert.True(t, errors.As(err, &cbErr))
+	assert.Equal(t, "secp256k1_pedersen_commitment_serialize", cbErr.Func)
+	assert.True(t, cbErr.Illegal)
+
+	blind := testingRand32()
+	_, err = Commit(ctx, blind[:], 10, &Generator{})
+	assert.True(t, errors.As(err, &cbErr))
+	assert.Equal(t, "secp256k1_pedersen_commit", cbErr.Func)
+
+	// a successful call right after a failure must not report a stale error
+	_, err = Commit(ctx, blind[:], 10, &GeneratorH)
+	assert.NoError(t, err)
+
+	assert.Panics(t, func() { GeneratorSerialize(ctx, &Generator{}) })
+}
+
 func testingRand32() [32]byte {
 	key := [32]byte{}
 	_, err := io.ReadFull(rand.Reader, key[:])
@@ -38,3 +108,183 @@ func testingRand32() [32]byte {
 	}
 	return key
 }
+
+func TestError(t *testing.T) {
+	_, err := CommitmentParse(SharedContext(ContextNone), make([]byte, 33))
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, "CommitmentParse", e.Op)
+	assert.Equal(t, -1, e.Index)
+	assert.Equal(t, 0, e.Code)
+	assert.True(t, errors.Is(err, ErrCommitmentParse))
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+	assert.False(t, errors.Is(err, ErrRetryable))
+	assert.EqualError(t, err, "CommitmentParse: unable to parse the data as a commitment")
+
+	overflow := make([]byte, 32)
+	for i := range overflow {
+		overflow[i] = 0xff
+	}
+	_, err = Commit(SharedContext(ContextSign), overflow, 10, &GeneratorH)
+	assert.True(t, errors.Is(err, ErrCommitmentCommit))
+	assert.True(t, errors.Is(err, ErrRetryable))
+	assert.False(t, errors.Is(err, ErrInvalidInput))
+
+	blind := testingRand32()
+	_, err = BlindSum(SharedContext(ContextNone), [][]byte{blind[:]}, [][]byte{blind[:], blind[:16]})
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 2, e.Index)
+	assert.True(t, errors.Is(err, ErrCommitmentBlindSize))
+	assert.EqualError(t, err, "BlindSum: input 2: blinding factor must be 32 bytes")
+
+	_, err = CommitmentParse(SharedContext(ContextNone), []byte{})
+	assert.True(t, errors.Is(err, ErrInvalidInput))
+
+	// errors of an unhashable type, as returned by a caller supplied
+	// io.Reader, are matched without panicking
+	_, err = GenerateSecretScalar(SharedContext(ContextNone), failingReader{})
+	assert.True(t, errors.As(err, &e))
+	assert.NotPanics(t, func() {
+		assert.False(t, errors.Is(err, ErrRetryable))
+		assert.True(t, errors.Is(err, ErrInvalidInput))
+	})
+}
+
+// unhashableError is an error whose dynamic type cannot be used as a map key
+type unhashableError []string
+
+func (e unhashableError) Error() string {
+	return e[0]
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, unhashableError{"read failed"}
+}
+
+func TestNilArguments(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	seckey := make([]byte, 32)
+	seckey[31] = 1
+	msg := make([]byte, 32)
+	_, pk, _ := EcPubkeyCreate(ctx, seckey)
+	_, sig, _ := EcdsaSign(ctx, msg, seckey)
+	_, schnorrSig, _ := SchnorrSignLegacy(ctx, msg, seckey)
+	_, xonly, _, _ := XOnlyPubkeyFromPubkey(ctx, pk)
+
+	tests := []struct {
+		name string
+		fn   func() error
+		err  error
+	}{
+		{"EcPubkeySerialize", func() error {
+			_, _, err := EcPubkeySerialize(ctx, nil, EcCompressed)
+			return err
+		}, ErrorPublicKeyNil},
+		{"Ecdh", func() error {
+			_, _, err := Ecdh(ctx, nil, seckey)
+			return err
+		}, ErrorPublicKeyNil},
+		{"EcdhWithHash", func() error {
+			_, _, err := EcdhWithHash(ctx, nil, seckey, nil)
+			return err
+		}, ErrorPublicKeyNil},
+		{"EcPubKeyTweakAdd", func() error {
+			_, err := EcPubKeyTweakAdd(ctx, nil, seckey)
+			return err
+		}, ErrorPublicKeyNil},
+		{"EcPubKeyTweakMul", func() error {
+			_, err := EcPubKeyTweakMul(ctx, nil, seckey)
+			return err
+		}, ErrorPublicKeyNil},
+		{"EcPubKeyNegate", func() error {
+			_, err := EcPubKeyNegate(ctx, nil)
+			return err
+		}, ErrorPublicKeyNil},
+		{"XOnlyPubkeyFromPubkey", func() error {
+			_, _, _, err := XOnlyPubkeyFromPubkey(ctx, nil)
+			return err
+		}, ErrorPublicKeyNil},
+		{"EcdsaVerify signature", func() error {
+			_, err := EcdsaVerify(ctx, nil, msg, pk)
+			return err
+		}, ErrorSignatureNil},
+		{"EcdsaVerify public key", func() error {
+			_, err := EcdsaVerify(ctx, sig, msg, nil)
+			return err
+		}, ErrorPublicKeyNil},
+		{"EcdsaSignatureNormalize", func() error {
+			_, _, err := EcdsaSignatureNormalize(ctx, nil)
+			return err
+		}, ErrorSignatureNil},
+		{"EcdsaSignatureSerializeCompact", func() error {
+			_, _, err := EcdsaSignatureSerializeCompact(ctx, nil)
+			return err
+		}, ErrorSignatureNil},
+		{"EcdsaSignatureSerializeDER", func() error {
+			_, _, err := EcdsaSignatureSerializeDER(ctx, nil)
+			return err
+		}, ErrorSignatureNil},
+		{"EcdsaRecoverableSignatureSerializeCompact", func() error {
+			_, _, err := EcdsaRecoverableSignatureSerializeCompact(ctx, nil)
+			return err
+		}, ErrorSignatureNil},
+		{"EcdsaRecoverableSignatureConvert", func() error {
+			_, _, err := EcdsaRecoverableSignatureConvert(ctx, nil)
+			return err
+		}, ErrorSignatureNil},
+		{"EcdsaRecover", func() error {
+			_, _, err := EcdsaRecover(ctx, nil, msg)
+			return err
+		}, ErrorSignatureNil},
+		{"SchnorrSignatureSerialize", func() error {
+			_, _, err := SchnorrSignatureSerialize(ctx, nil)
+			return err
+		}, ErrorSchnorrSignatureNil},
+		{"SchnorrVerifyLegacy signature", func() error {
+			_, err := SchnorrVerifyLegacy(ctx, nil, msg, pk)
+			return err
+		}, ErrorSchnorrSignatureNil},
+		{"SchnorrVerifyLegacy public key", func() error {
+			_, err := SchnorrVerifyLegacy(ctx, schnorrSig, msg, nil)
+			return err
+		}, ErrorPublicKeyNil},
+		{"SchnorrVerify signature", func() error {
+			_, err := SchnorrVerify(ctx, nil, msg, xonly)
+			return err
+		}, ErrorSchnorrSignatureNil},
+		{"SchnorrVerify public key", func() error {
+			_, err := SchnorrVerify(ctx, schnorrSig, msg, nil)
+			return err
+		}, ErrorPublicKeyNil},
+		{"WhitelistSignatureSerialize", func() error {
+			_, _, err := WhitelistSignatureSerialize(ctx, nil)
+			return err
+		}, ErrorWhitelistSignatureNil},
+		{"WhitelistVerify", func() error {
+			_, err := WhitelistVerify(ctx, nil, []*PublicKey{pk}, []*PublicKey{pk}, pk)
+			return err
+		}, ErrorWhitelistSignatureNil},
+		{"MusigPartialSignatureSerialize", func() error {
+			_, _, err := MusigPartialSignatureSerialize(ctx, nil)
+			return err
+		}, ErrorMusigPartialSignatureNil},
+	}
+	for _, tt := range tests {
+		var err error
+		if assert.NotPanics(t, func() { err = tt.fn() }, tt.name) {
+			assert.True(t, errors.Is(err, tt.err), "%s: %v", tt.name, err)
+		}
+	}
+
+	// methods without an error return report nil signatures as empty
+	assert.NotPanics(t, func() {
+		assert.Equal(t, 0, (*WhitelistSignature)(nil).NKeys())
+		assert.Equal(t, -1, (*RecoverableSignature)(nil).RecoveryID())
+		assert.Nil(t, (*RecoverableSignature)(nil).ToSignature())
+		assert.False(t, (*Signature)(nil).Normalize())
+	})
+}
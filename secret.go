@@ -0,0 +1,71 @@
+package secp256k1
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var ErrorSecretScalarNil = errors.New("secret scalar is nil")
+
+// redacted is printed in place of the value of a SecretScalar
+const redacted = "[REDACTED]"
+
+// SecretScalar holds a 32-byte secret: a secret key, a blinding factor or a
+// rangeproof nonce, usually obtained from GenerateSecretScalar. The
+// functions with a Secret suffix, such as EcdhSecret, EcPrivKeyTweakAddSecret,
+// CommitSecret, BlindSumSecret or RangeProofRewindSecret, take and return it
+// by pointer without copying the secret, and those modifying a secret key
+// modify the scalar in place.
+//
+// Its value is never printed by the fmt package, whatever the verb, and it
+// should be wiped with Zero as soon as it is no longer needed. Since arrays
+// are copied on assignment, it is best passed around by pointer.
+type SecretScalar [32]byte
+
+// PrivateKey is a SecretScalar holding a secret key
+type PrivateKey = SecretScalar
+
+// NewSecretScalar returns a SecretScalar holding a copy of b, which must be
+// 32 bytes. The caller is responsible for wiping b.
+func NewSecretScalar(b []byte) (*SecretScalar, error) {
+	if len(b) != LenPrivateKey {
+		return nil, newError("NewSecretScalar", 0, ErrorPrivateKeySize)
+	}
+	s := new(SecretScalar)
+	copy(s[:], b)
+	return s, nil
+}
+
+// Zero wipes the scalar
+func (s *SecretScalar) Zero() {
+	zero(s[:])
+}
+
+// IsZero reports whether the scalar is all zeros, for instance after Zero
+func (s *SecretScalar) IsZero() bool {
+	var z SecretScalar
+	return s.Equal(&z)
+}
+
+// Equal compares two scalars in constant time
+func (s *SecretScalar) Equal(other *SecretScalar) bool {
+	return subtle.ConstantTimeCompare(s[:], other[:]) == 1
+}
+
+// String returns a placeholder, never the value of the scalar
+func (s SecretScalar) String() string {
+	return redacted
+}
+
+// GoString returns a placeholder, never the value of the scalar
+func (s SecretScalar) GoString() string {
+	return redacted
+}
+
+// Format implements fmt.Formatter so that the value of the scalar is not
+// printed by any verb, including %x and %v.
+func (s SecretScalar) Format(f fmt.State, verb rune) {
+	io.WriteString(f, redacted)
+}
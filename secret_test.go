@@ -0,0 +1,218 @@
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretScalarFormat(t *testing.T) {
+	b, _ := hex.DecodeString("d90314455f64c385db12f629c2adbecc576baebdfe70905a412747a689872760")
+	s, err := NewSecretScalar(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wrapped := struct {
+		Blind *SecretScalar
+		Key   PrivateKey
+	}{s, *s}
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%x", "%X", "%d", "%q"} {
+		for _, value := range []interface{}{*s, s, wrapped} {
+			out := fmt.Sprintf(format, value)
+			assert.Contains(t, out, redacted, format)
+			assert.False(t, strings.Contains(strings.ToLower(out), "d90314"), format)
+			assert.False(t, strings.Contains(out, "217"), format)
+		}
+	}
+	assert.Equal(t, redacted, s.String())
+
+	_, err = NewSecretScalar(b[1:])
+	assert.True(t, errors.Is(err, ErrorPrivateKeySize))
+}
+
+func TestSecretScalarEqualAndZero(t *testing.T) {
+	ctx, _ := ContextCreate(ContextNone)
+	defer ContextDestroy(ctx)
+
+	s, err := GenerateSecretScalar(ctx, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _ := NewSecretScalar(s[:])
+	assert.True(t, s.Equal(other))
+	other[31] ^= 1
+	assert.False(t, s.Equal(other))
+	assert.False(t, s.IsZero())
+
+	s.Zero()
+	assert.True(t, s.IsZero())
+	assert.Equal(t, make([]byte, 32), s[:])
+}
+
+func TestSecretScalarBlinding(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	gen, err := GeneratorGenerate(ctx, bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blind, _ := GenerateSecretScalar(ctx, rand.Reader)
+	nonce, _ := GenerateSecretScalar(ctx, rand.Reader)
+	defer blind.Zero()
+	defer nonce.Zero()
+
+	var sum SecretScalar
+	sum, err = BlindSum(ctx, [][]byte{blind[:]}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, sum.Equal(blind))
+
+	commit, err := Commit(ctx, blind[:], 10, gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := RangeProofSign(ctx, 0, commit, *blind, *nonce, 0, 0, 10, nil, nil, gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rewound SecretScalar
+	rewound, value, _, _, _, err := RangeProofRewind(ctx, commit, proof, *nonce, nil, gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(10), value)
+	assert.True(t, rewound.Equal(blind))
+}
+
+func TestSecretScalarBlindingPointers(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	gen, err := GeneratorGenerate(ctx, bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	blind, _ := GenerateSecretScalar(ctx, rand.Reader)
+	other, _ := GenerateSecretScalar(ctx, rand.Reader)
+	nonce, _ := GenerateSecretScalar(ctx, rand.Reader)
+	defer blind.Zero()
+	defer other.Zero()
+	defer nonce.Zero()
+
+	sum, err := BlindSumSecret(ctx, []*SecretScalar{blind, other}, []*SecretScalar{other})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, sum.Equal(blind))
+
+	// the same last blinding factor as with slices
+	generatorBlinds := []*SecretScalar{other, nonce}
+	last, err := BlindGeneratorBlindSumSecret(ctx, []uint64{10, 10}, generatorBlinds, []*SecretScalar{blind}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := BlindGeneratorBlindSum(ctx, []uint64{10, 10}, [][]byte{other[:], nonce[:]}, [][]byte{blind[:]}, 1)
+	assert.Equal(t, expected[:], last[:])
+
+	commit, err := CommitSecret(ctx, blind, 10, gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := RangeProofSignSecret(ctx, 0, commit, blind, nonce, 0, 0, 10, nil, nil, gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewound, value, _, _, _, err := RangeProofRewindSecret(ctx, commit, proof, nonce, nil, gen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(10), value)
+	assert.True(t, rewound.Equal(blind))
+
+	rewound, _, _, _, _, err = RangeProofRewindSecret(ctx, commit, proof, other, nil, gen)
+	assert.Nil(t, rewound)
+	assert.True(t, errors.Is(err, ErrRangeProofRewind))
+
+	_, err = CommitSecret(ctx, nil, 10, gen)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	_, err = BlindSumSecret(ctx, []*SecretScalar{blind}, []*SecretScalar{nil})
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	_, err = BlindGeneratorBlindSumSecret(ctx, []uint64{10, 10}, []*SecretScalar{other, nil}, []*SecretScalar{blind}, 1)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	assert.Equal(t, 1, err.(*Error).Index)
+	_, err = BlindGeneratorBlindSumSecret(ctx, []uint64{10, 10}, []*SecretScalar{other, nonce}, []*SecretScalar{nil}, 1)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	assert.Equal(t, 2, err.(*Error).Index)
+	_, err = RangeProofSignSecret(ctx, 0, commit, blind, nil, 0, 0, 10, nil, nil, gen)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	_, _, _, _, _, err = RangeProofRewindSecret(ctx, commit, proof, nil, nil, gen)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+}
+
+func TestSecretScalarKeys(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	key, _ := GenerateSecretScalar(ctx, rand.Reader)
+	other, _ := GenerateSecretScalar(ctx, rand.Reader)
+	defer key.Zero()
+	defer other.Zero()
+
+	_, pk, err := EcPubkeyCreateSecret(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherPk, _ := EcPubkeyCreateSecret(ctx, other)
+
+	_, secret, err := EcdhSecret(ctx, otherPk, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, expected, _ := Ecdh(ctx, pk, other[:])
+	assert.Equal(t, expected, secret[:])
+
+	_, hashed, err := EcdhWithHashSecret(ctx, otherPk, key, EcdhHashSHA256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, expected, hashed)
+
+	// the scalars are modified in place
+	tweak := bytes.Repeat([]byte{1}, 32)
+	tweaked, _ := NewSecretScalar(key[:])
+	defer tweaked.Zero()
+	_, err = EcPrivKeyTweakAddSecret(ctx, tweaked, tweak)
+	assert.NoError(t, err)
+	_, err = EcPrivKeyTweakMulSecret(ctx, tweaked, tweak)
+	assert.NoError(t, err)
+	_, err = EcPrivKeyNegateSecret(ctx, tweaked)
+	assert.NoError(t, err)
+	expected = append([]byte{}, key[:]...)
+	EcPrivKeyTweakAdd(ctx, expected, tweak)
+	EcPrivKeyTweakMul(ctx, expected, tweak)
+	EcPrivKeyNegate(ctx, expected)
+	assert.Equal(t, expected, tweaked[:])
+
+	_, _, err = EcPubkeyCreateSecret(ctx, nil)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	_, _, err = EcdhSecret(ctx, pk, nil)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	_, _, err = EcdhWithHashSecret(ctx, pk, nil, nil)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	_, err = EcPrivKeyTweakAddSecret(ctx, nil, tweak)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	_, err = EcPrivKeyTweakMulSecret(ctx, nil, tweak)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+	_, err = EcPrivKeyNegateSecret(ctx, nil)
+	assert.True(t, errors.Is(err, ErrorSecretScalarNil))
+}
@@ -0,0 +1,110 @@
+package secp256k1
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+)
+
+const (
+	// Length of elements byte representations
+	LenMasterBlindingKey int = 32
+)
+
+var (
+	// slip21Seed is the key of the HMAC-SHA512 deriving the SLIP-21 master
+	// node from a seed
+	slip21Seed = []byte("Symmetric key seed")
+	// slip77Label is the SLIP-21 label of the master blinding key
+	slip77Label = []byte("SLIP-0077")
+)
+
+var (
+	ErrorMasterBlindingKeySize = errors.New("master blinding key must be exactly 32 bytes")
+	ErrorBlindingKey           = errors.New("invalid blinding key, derived from an unlucky script")
+)
+
+// MasterBlindingKey is a SLIP-77 master blinding key, from which the
+// blinding key pair of every script of a wallet is derived, as done by
+// Elements Core, Green and Jade. It must be handled as a secret: knowing it
+// reveals the amounts and assets of all the outputs of the wallet.
+type MasterBlindingKey struct {
+	key *SecretScalar
+}
+
+// MasterBlindingKeyFromSeed derives the master blinding key of a BIP39 seed
+// as defined by SLIP-77: the key of the SLIP-21 node at the path
+// ["SLIP-0077"].
+func MasterBlindingKeyFromSeed(seed []byte) (*MasterBlindingKey, error) {
+	if len(seed) < MinSeedBytes || len(seed) > MaxSeedBytes {
+		return nil, newError("MasterBlindingKeyFromSeed", 0, ErrorSeedSize)
+	}
+
+	mac := hmac.New(sha512.New, slip21Seed)
+	mac.Write(seed)
+	root := mac.Sum(nil)
+	defer zero(root)
+
+	mac = hmac.New(sha512.New, root[:32])
+	mac.Write([]byte{0})
+	mac.Write(slip77Label)
+	node := mac.Sum(nil)
+	defer zero(node)
+
+	return MasterBlindingKeyFromBytes(node[32:])
+}
+
+// MasterBlindingKeyFromBytes returns the master blinding key holding a copy
+// of key, which must be 32 bytes, such as the one exported by the
+// dumpmasterblindingkey RPC of Elements Core.
+func MasterBlindingKeyFromBytes(key []byte) (*MasterBlindingKey, error) {
+	if len(key) != LenMasterBlindingKey {
+		return nil, newError("MasterBlindingKeyFromBytes", 0, ErrorMasterBlindingKeySize)
+	}
+	s, _ := NewSecretScalar(key)
+	return &MasterBlindingKey{s}, nil
+}
+
+// Bytes returns a copy of the master blinding key, to be wiped by the
+// caller
+func (k *MasterBlindingKey) Bytes() []byte {
+	return append([]byte{}, k.key[:]...)
+}
+
+// BlindingPrivateKey returns the blinding private key of the output script
+// scriptPubKey, HMAC-SHA256(master blinding key, scriptPubKey). The key
+// unblinds the outputs sent to the confidential address of the script.
+func (k *MasterBlindingKey) BlindingPrivateKey(scriptPubKey []byte) (*PrivateKey, error) {
+	mac := hmac.New(sha256.New, k.key[:])
+	mac.Write(scriptPubKey)
+	sum := mac.Sum(nil)
+	defer zero(sum)
+
+	result, err := EcSeckeyVerify(SharedContext(ContextNone), sum)
+	if err != nil {
+		return nil, err
+	}
+	if result != 1 {
+		return nil, newError("BlindingPrivateKey", result, ErrorBlindingKey)
+	}
+	return NewSecretScalar(sum)
+}
+
+// BlindingPublicKey returns the blinding public key of the output script
+// scriptPubKey, the one embedded in its confidential address.
+func (k *MasterBlindingKey) BlindingPublicKey(scriptPubKey []byte) (*PublicKey, error) {
+	privateKey, err := k.BlindingPrivateKey(scriptPubKey)
+	if err != nil {
+		return nil, err
+	}
+	defer privateKey.Zero()
+
+	_, publicKey, err := EcPubkeyCreate(SharedContext(ContextSign), privateKey[:])
+	return publicKey, err
+}
+
+// Zero wipes the master blinding key, after which it is no longer usable
+func (k *MasterBlindingKey) Zero() {
+	k.key.Zero()
+}
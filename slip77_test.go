@@ -0,0 +1,80 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMasterBlindingKey(t *testing.T) {
+	file, err := ioutil.ReadFile("testdata/slip77.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tests struct {
+		Slip77 []struct {
+			Seed              string `json:"seed"`
+			MasterBlindingKey string `json:"masterBlindingKey"`
+			Scripts           []struct {
+				Script             string `json:"script"`
+				BlindingPrivateKey string `json:"blindingPrivateKey"`
+				BlindingPublicKey  string `json:"blindingPublicKey"`
+			} `json:"scripts"`
+		} `json:"slip77"`
+	}
+	if err := json.Unmarshal(file, &tests); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range tests.Slip77 {
+		seed, _ := hex.DecodeString(v.Seed)
+		key, err := MasterBlindingKeyFromSeed(seed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, v.MasterBlindingKey, hex.EncodeToString(key.Bytes()))
+
+		imported, _ := hex.DecodeString(v.MasterBlindingKey)
+		fromBytes, err := MasterBlindingKeyFromBytes(imported)
+		assert.NoError(t, err)
+
+		for _, s := range v.Scripts {
+			script, _ := hex.DecodeString(s.Script)
+			for _, k := range []*MasterBlindingKey{key, fromBytes} {
+				privateKey, err := k.BlindingPrivateKey(script)
+				assert.NoError(t, err)
+				assert.Equal(t, s.BlindingPrivateKey, hex.EncodeToString(privateKey[:]))
+
+				publicKey, err := k.BlindingPublicKey(script)
+				assert.NoError(t, err)
+				assert.Equal(t, s.BlindingPublicKey, publicKey.String())
+			}
+		}
+	}
+}
+
+func TestMasterBlindingKeyInvalid(t *testing.T) {
+	_, err := MasterBlindingKeyFromSeed(make([]byte, 15))
+	assert.True(t, errors.Is(err, ErrorSeedSize))
+
+	_, err = MasterBlindingKeyFromBytes(make([]byte, 31))
+	assert.True(t, errors.Is(err, ErrorMasterBlindingKeySize))
+}
+
+func TestMasterBlindingKeyZero(t *testing.T) {
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	key, _ := MasterBlindingKeyFromSeed(seed)
+	bytes := key.Bytes()
+
+	assert.False(t, strings.Contains(fmt.Sprintf("%v %+v %#v", key, *key, *key), hex.EncodeToString(bytes)))
+
+	key.Zero()
+	assert.Equal(t, make([]byte, 32), key.Bytes())
+	assert.NotEqual(t, make([]byte, 32), bytes)
+}
@@ -24,22 +24,27 @@ import "C"
 import (
 	"encoding/hex"
 	"errors"
+	"runtime"
 )
 
 const (
 	// SurjectionProofSerializationBytesMax is the maximum number of bytes a serialized surjection proof requires
 	SurjectionProofSerializationBytesMax = C.SECP256K1_SURJECTIONPROOF_SERIALIZATION_BYTES_MAX
+)
 
-	// ErrSurjectionProofParsing error message for proof parsing function
-	ErrSurjectionProofParsing string = "surjection proof parsing failed"
-	// ErrSurjectionProofGeneration error message for proof generation function
-	ErrSurjectionProofGeneration string = "surjection proof generation failed"
-	// ErrSurjectionProofAllocation error message for proof allocation function
-	ErrSurjectionProofAllocation string = "surjection proof allocation/initialization failed"
-	// ErrSurjectionProofInitialization error message for proof initilization function
-	ErrSurjectionProofInitialization string = "surjection proof initialization failed"
-	// ErrSurjectionProofSerialization error message for proof serialization function
-	ErrSurjectionProofSerialization string = "surjection proof serialization failed"
+var (
+	// ErrSurjectionProofParsing error for proof parsing function
+	ErrSurjectionProofParsing = errors.New("surjection proof parsing failed")
+	// ErrSurjectionProofGeneration error for proof generation function
+	ErrSurjectionProofGeneration = errors.New("surjection proof generation failed")
+	// ErrSurjectionProofAllocation error for proof allocation function
+	ErrSurjectionProofAllocation = errors.New("surjection proof allocation/initialization failed")
+	// ErrSurjectionProofInitialization error for proof initilization function
+	ErrSurjectionProofInitialization = errors.New("surjection proof initialization failed")
+	// ErrSurjectionProofSerialization error for proof serialization function
+	ErrSurjectionProofSerialization = errors.New("surjection proof serialization failed")
+	// ErrSurjectionProofDestroyed error for a proof used after being destroyed
+	ErrSurjectionProofDestroyed = errors.New("surjection proof has been destroyed")
 )
 
 // SurjectionProofSerializationBytesCalc calculates the number of bytes a
@@ -63,8 +68,35 @@ func SurjectionProofSerializationBytesCalc(nInputs int, nUsedInputs int) int {
 //
 //  The representation is exposed to allow creation of these objects on the
 //  stack; please *do not* use these internals directly.
+//
+//  Proofs returned by SurjectionProofAllocateInitialized live on the C heap
+//  and are released when the object is garbage collected, or earlier with
+//  Close or SurjectionProofDestroy.
 type SurjectionProof struct {
-	proof *C.secp256k1_surjectionproof
+	proof     *C.secp256k1_surjectionproof
+	allocated bool
+}
+
+// check returns an error if the proof is nil or has been destroyed.
+func (proof *SurjectionProof) check() error {
+	if proof == nil || proof.proof == nil {
+		return ErrSurjectionProofDestroyed
+	}
+	return nil
+}
+
+// Close releases the memory held by the proof. It is safe to call it more
+// than once, while any later use of the proof returns an error.
+func (proof *SurjectionProof) Close() error {
+	if proof == nil || proof.proof == nil {
+		return nil
+	}
+	if proof.allocated {
+		C.secp256k1_surjectionproof_destroy(proof.proof)
+		runtime.SetFinalizer(proof, nil)
+	}
+	proof.proof = nil
+	return nil
 }
 
 // Bytes converts a surjection proof object to a byte slice
@@ -120,14 +152,26 @@ func SurjectionProofParse(
 	proof *SurjectionProof,
 	err error,
 ) {
-	proof = newSurjectionProof()
-	if 1 != C.secp256k1_surjectionproof_parse(
-		context.ctx,
-		proof.proof,
-		cBuf(bytes),
-		C.size_t(len(bytes))) {
+	if err = context.check(); err != nil {
+		err = newError("SurjectionProofParse", 0, err)
+		return
+	}
+	defer runtime.KeepAlive(context)
 
-		err = errors.New(ErrSurjectionProofParsing)
+	proof = newSurjectionProof()
+	result, err := call("secp256k1_surjectionproof_parse", func() C.int {
+		return C.secp256k1_surjectionproof_parse(
+			context.ctx,
+			proof.proof,
+			cBuf(bytes),
+			C.size_t(len(bytes)))
+	})
+	if err != nil {
+		err = newError("SurjectionProofParse", 0, err)
+		return
+	}
+	if 1 != result {
+		err = newError("SurjectionProofParse", result, ErrSurjectionProofParsing)
 	}
 
 	return
@@ -149,16 +193,32 @@ func SurjectionProofSerialize(
 	bytes []byte,
 	err error,
 ) {
+	if err = context.check(); err != nil {
+		err = newError("SurjectionProofSerialize", 0, err)
+		return
+	}
+	if err = proof.check(); err != nil {
+		err = newError("SurjectionProofSerialize", 0, err)
+		return
+	}
+	defer runtime.KeepAlive(context)
+	defer runtime.KeepAlive(proof)
+
 	var data [SurjectionProofSerializationBytesMax]C.uchar
 	size := C.size_t(len(data))
-	if 1 != C.secp256k1_surjectionproof_serialize(
-		context.ctx,
-		&data[0],
-		&size,
-		proof.proof,
-	) {
-
-		return nil, errors.New(ErrSurjectionProofSerialization)
+	result, err := call("secp256k1_surjectionproof_serialize", func() C.int {
+		return C.secp256k1_surjectionproof_serialize(
+			context.ctx,
+			&data[0],
+			&size,
+			proof.proof,
+		)
+	})
+	if err != nil {
+		return nil, newError("SurjectionProofSerialize", 0, err)
+	}
+	if 1 != result {
+		return nil, newError("SurjectionProofSerialize", result, ErrSurjectionProofSerialization)
 	}
 
 	return goBytes(data[:], C.int(size)), nil
@@ -249,10 +309,18 @@ func SurjectionProofNTotalInputs(
 ) (
 	number int,
 ) {
-	return int(C.secp256k1_surjectionproof_n_total_inputs(
-		context.ctx,
-		proof.proof,
-	))
+	if context.check() != nil || proof.check() != nil {
+		return 0
+	}
+	defer runtime.KeepAlive(context)
+	defer runtime.KeepAlive(proof)
+
+	return mustCall("secp256k1_surjectionproof_n_total_inputs", func() C.int {
+		return C.int(C.secp256k1_surjectionproof_n_total_inputs(
+			context.ctx,
+			proof.proof,
+		))
+	})
 }
 
 // SurjectionProofNUsedInputs returns the actual number of inputs that a proof uses
@@ -265,10 +333,18 @@ func SurjectionProofNUsedInputs(
 ) (
 	number int,
 ) {
-	return int(C.secp256k1_surjectionproof_n_used_inputs(
-		context.ctx,
-		proof.proof,
-	))
+	if context.check() != nil || proof.check() != nil {
+		return 0
+	}
+	defer runtime.KeepAlive(context)
+	defer runtime.KeepAlive(proof)
+
+	return mustCall("secp256k1_surjectionproof_n_used_inputs", func() C.int {
+		return C.int(C.secp256k1_surjectionproof_n_used_inputs(
+			context.ctx,
+			proof.proof,
+		))
+	})
 }
 
 // SurjectionProofInitialize proof initialization function; decides on inputs to use
@@ -354,11 +430,12 @@ func SurjectionProofAllocateInitialized(
 
 // SurjectionProofDestroy proof destroy function
 // deallocates the struct that was allocated with secp256k1_surjectionproof_allocate_initialized
+// It is equivalent to proof.Close() and it is safe to call it more than once.
 //	 In: proof: pointer to SurjectionProof struct
 func SurjectionProofDestroy(
 	proof *SurjectionProof,
 ) {
-	C.secp256k1_surjectionproof_destroy(proof.proof)
+	proof.Close()
 }
 
 // SurjectionProofGenerate proof generation function
@@ -423,6 +500,11 @@ func surjectionProofInitialize(
 	nMaxIterations int,
 	seed32 []byte,
 ) (*SurjectionProof, int, error) {
+	if err := context.check(); err != nil {
+		return nil, 0, newError("SurjectionProofInitialize", 0, err)
+	}
+	defer runtime.KeepAlive(context)
+
 	// cache data locally to prevent unexpected modifications
 	data := make([]C.secp256k1_fixed_asset_tag, nInputs)
 	ptrs := make([]*C.secp256k1_fixed_asset_tag, nInputs)
@@ -434,18 +516,24 @@ func surjectionProofInitialize(
 
 	inputIndex := C.size_t(0)
 	proof := newSurjectionProof()
-	if 0 == int(C.secp256k1_surjectionproof_initialize(
-		context.ctx,
-		proof.proof,
-		&inputIndex,
-		ptrs[0],
-		C.size_t(nInputs),
-		C.size_t(nInputTagsToUse),
-		fixedOutputTag.tag,
-		C.size_t(nMaxIterations),
-		cBuf(seed32),
-	)) {
-		return nil, 0, errors.New(ErrSurjectionProofInitialization)
+	result, err := call("secp256k1_surjectionproof_initialize", func() C.int {
+		return C.secp256k1_surjectionproof_initialize(
+			context.ctx,
+			proof.proof,
+			&inputIndex,
+			ptrs[0],
+			C.size_t(nInputs),
+			C.size_t(nInputTagsToUse),
+			fixedOutputTag.tag,
+			C.size_t(nMaxIterations),
+			cBuf(seed32),
+		)
+	})
+	if err != nil {
+		return nil, 0, newError("SurjectionProofInitialize", 0, err)
+	}
+	if 0 == result {
+		return nil, 0, newError("SurjectionProofInitialize", result, ErrSurjectionProofInitialization)
 	}
 
 	return proof, int(inputIndex), nil
@@ -460,6 +548,11 @@ func surjectionProofAllocateInitialized(
 	nMaxIterations int,
 	seed32 []byte,
 ) (int, *SurjectionProof, int, error) {
+	if err := context.check(); err != nil {
+		return -1, nil, -1, newError("SurjectionProofAllocateInitialized", 0, err)
+	}
+	defer runtime.KeepAlive(context)
+
 	// cache data locally to prevent unexpected modifications
 	data := make([]C.secp256k1_fixed_asset_tag, nInputs)
 	ptrs := make([]*C.secp256k1_fixed_asset_tag, nInputs)
@@ -470,23 +563,30 @@ func surjectionProofAllocateInitialized(
 	}
 
 	inputIndex := C.size_t(0)
-	proof := SurjectionProof{}
-	nIters := int(C.secp256k1_surjectionproof_allocate_initialized(
-		context.ctx,
-		&proof.proof,
-		&inputIndex,
-		ptrs[0],
-		C.size_t(nInputs),
-		C.size_t(nInputTagsToUse),
-		fixedOutputTag.tag,
-		C.size_t(nMaxIterations),
-		cBuf(seed32),
-	))
+	proof := &SurjectionProof{allocated: true}
+	nIters, err := call("secp256k1_surjectionproof_allocate_initialized", func() C.int {
+		return C.secp256k1_surjectionproof_allocate_initialized(
+			context.ctx,
+			&proof.proof,
+			&inputIndex,
+			ptrs[0],
+			C.size_t(nInputs),
+			C.size_t(nInputTagsToUse),
+			fixedOutputTag.tag,
+			C.size_t(nMaxIterations),
+			cBuf(seed32),
+		)
+	})
+	if err != nil {
+		return -1, nil, -1, newError("SurjectionProofAllocateInitialized", 0, err)
+	}
 	if nIters <= 0 {
-		return -1, nil, -1, errors.New(ErrSurjectionProofAllocation)
+		return -1, nil, -1, newError("SurjectionProofAllocateInitialized", 0, ErrSurjectionProofAllocation)
 	}
 
-	return nIters, &proof, int(inputIndex), nil
+	runtime.SetFinalizer(proof, (*SurjectionProof).Close)
+
+	return nIters, proof, int(inputIndex), nil
 }
 
 func surjectionProofGenerate(
@@ -499,6 +599,15 @@ func surjectionProofGenerate(
 	inputBlindingKey []byte,
 	outputBlindingKey []byte,
 ) error {
+	if err := context.require(ContextBoth); err != nil {
+		return newError("SurjectionProofGenerate", 0, err)
+	}
+	if err := proof.check(); err != nil {
+		return newError("SurjectionProofGenerate", 0, err)
+	}
+	defer runtime.KeepAlive(context)
+	defer runtime.KeepAlive(proof)
+
 	data := make([]C.secp256k1_generator, nInputs)
 	ptrs := make([]*C.secp256k1_generator, nInputs)
 	for i := 0; i < nInputs; i++ {
@@ -508,17 +617,23 @@ func surjectionProofGenerate(
 		ptrs[i] = &data[i]
 	}
 
-	if 1 != C.secp256k1_surjectionproof_generate(
-		context.ctx,
-		proof.proof,
-		ptrs[0],
-		C.size_t(nInputs), //len(ephemeralInputTags)),
-		ephemeralOutputTag.gen,
-		C.size_t(inputIndex),
-		cBuf(inputBlindingKey),
-		cBuf(outputBlindingKey),
-	) {
-		return errors.New(ErrSurjectionProofGeneration)
+	result, err := call("secp256k1_surjectionproof_generate", func() C.int {
+		return C.secp256k1_surjectionproof_generate(
+			context.ctx,
+			proof.proof,
+			ptrs[0],
+			C.size_t(nInputs), //len(ephemeralInputTags)),
+			ephemeralOutputTag.gen,
+			C.size_t(inputIndex),
+			cBuf(inputBlindingKey),
+			cBuf(outputBlindingKey),
+		)
+	})
+	if err != nil {
+		return newError("SurjectionProofGenerate", 0, err)
+	}
+	if 1 != result {
+		return newError("SurjectionProofGenerate", result, ErrSurjectionProofGeneration)
 	}
 	return nil
 }
@@ -530,6 +645,12 @@ func surjectionProofVerify(
 	nInputs int,
 	ephemeralOutputTag *Generator,
 ) bool {
+	if context.check() != nil || proof.check() != nil {
+		return false
+	}
+	defer runtime.KeepAlive(context)
+	defer runtime.KeepAlive(proof)
+
 	// cache data locally to prevent unexpected modifications
 	data := make([]C.secp256k1_generator, nInputs)
 	ptrs := make([]*C.secp256k1_generator, nInputs)
@@ -538,11 +659,13 @@ func surjectionProofVerify(
 		data[i] = *(e.gen)
 		ptrs[i] = &data[i]
 	}
-	return 1 == C.secp256k1_surjectionproof_verify(
-		context.ctx,
-		proof.proof,
-		ptrs[0],
-		C.size_t(nInputs),
-		ephemeralOutputTag.gen,
-	)
+	return 1 == mustCall("secp256k1_surjectionproof_verify", func() C.int {
+		return C.secp256k1_surjectionproof_verify(
+			context.ctx,
+			proof.proof,
+			ptrs[0],
+			C.size_t(nInputs),
+			ephemeralOutputTag.gen,
+		)
+	})
 }
@@ -95,3 +95,46 @@ func TestSurjectionProofGenerateAndVerify(t *testing.T) {
 		assert.Equal(t, true, SurjectionProofVerify(ctx, proof, ephemeralInTags, ephemeralOutTag))
 	}
 }
+
+func TestSurjectionProofAllocateAndDestroy(t *testing.T) {
+	file, err := ioutil.ReadFile("testdata/surjectionproof.json")
+	assert.NoError(t, err)
+
+	var tests map[string]interface{}
+	json.Unmarshal(file, &tests)
+	v := tests["initializeAndSerialize"].([]interface{})[0].(map[string]interface{})
+
+	ctx, _ := ContextCreate(ContextNone)
+	defer ContextDestroy(ctx)
+
+	seed, _ := hex.DecodeString(v["seed"].(string))
+	fixedOutputTag, err := FixedAssetTagFromHex(v["outputTag"].(string))
+	assert.NoError(t, err)
+	fixedInputTags := []*FixedAssetTag{}
+	for _, inTag := range v["inputTags"].([]interface{}) {
+		fixedAssetTag, err := FixedAssetTagFromHex(inTag.(string))
+		assert.NoError(t, err)
+		fixedInputTags = append(fixedInputTags, fixedAssetTag)
+	}
+
+	nIters, proof, _, err := SurjectionProofAllocateInitialized(
+		ctx,
+		fixedInputTags,
+		int(v["inputTagsToUse"].(float64)),
+		fixedOutputTag,
+		int(v["maxIterations"].(float64)),
+		seed,
+	)
+	assert.NoError(t, err)
+	assert.True(t, nIters > 0)
+	expected := v["expected"].(map[string]interface{})
+	assert.Equal(t, expected["proof"].(string), proof.String())
+
+	SurjectionProofDestroy(proof)
+	assert.NoError(t, proof.Close())
+
+	_, err = SurjectionProofSerialize(ctx, proof)
+	assert.Error(t, err)
+	assert.Equal(t, 0, SurjectionProofNTotalInputs(ctx, proof))
+	assert.False(t, SurjectionProofVerify(ctx, proof, nil, &GeneratorH))
+}
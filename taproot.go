@@ -0,0 +1,197 @@
+package secp256k1
+
+import (
+	"bytes"
+	"errors"
+)
+
+const (
+	// Length of elements byte representations
+	LenTaprootHash int = 32
+
+	// TaprootLeafTapscript is the leaf version of tapscript on Bitcoin
+	TaprootLeafTapscript byte = 0xc0
+	// TaprootLeafTapscriptElements is the leaf version of tapscript on
+	// Elements
+	TaprootLeafTapscriptElements byte = 0xc4
+	// TaprootControlBlockMaxPath is the maximum number of hashes of the
+	// Merkle path of a control block, that is the maximum depth of a tree
+	TaprootControlBlockMaxPath = 128
+)
+
+var (
+	ErrorMerkleRootSize       = errors.New("merkle root must be empty or exactly 32 bytes")
+	ErrorTaprootHashSize      = errors.New("taproot hash must be exactly 32 bytes")
+	ErrorLeafVersion          = errors.New("leaf version must have the lowest bit unset")
+	ErrorOutputKeyParity      = errors.New("output key parity must be 0 or 1")
+	ErrorControlBlockPathSize = errors.New("control block path has too many hashes")
+)
+
+// TaprootTags holds the tags of the BIP340 tagged hashes used to build a
+// Taproot script tree and to tweak its internal key. Bitcoin and Elements
+// use different tags, so that a script tree committed to on one chain is
+// never valid on the other.
+type TaprootTags struct {
+	Leaf   string
+	Branch string
+	Tweak  string
+}
+
+var (
+	// BitcoinTaprootTags are the tags defined by BIP341
+	BitcoinTaprootTags = TaprootTags{
+		Leaf:   "TapLeaf",
+		Branch: "TapBranch",
+		Tweak:  "TapTweak",
+	}
+	// ElementsTaprootTags are the tags used by Elements
+	ElementsTaprootTags = TaprootTags{
+		Leaf:   "TapLeaf/elements",
+		Branch: "TapBranch/elements",
+		Tweak:  "TapTweak/elements",
+	}
+)
+
+// TapLeafHash returns the hash of a leaf of a script tree, committing to the
+// leaf version and to the compact size prefixed script.
+func TapLeafHash(tags TaprootTags, leafVersion byte, script []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(leafVersion)
+	writeVarString(&buf, string(script))
+	return TaggedHash(tags.Leaf, buf.Bytes())
+}
+
+// TapBranchHash returns the hash of a branch of a script tree given the
+// hashes of its two children, which are sorted so that the result does not
+// depend on their order.
+func TapBranchHash(tags TaprootTags, a, b []byte) []byte {
+	if bytes.Compare(a, b) > 0 {
+		a, b = b, a
+	}
+	return TaggedHash(tags.Branch, a, b)
+}
+
+// TapTweakHash returns the tweak committing the internal key to the Merkle
+// root of a script tree. The Merkle root is empty for an output that can
+// only be spent through the key path.
+func TapTweakHash(tags TaprootTags, internalKey *XOnlyPublicKey, merkleRoot []byte) []byte {
+	return TaggedHash(tags.Tweak, internalKey.Serialize(), merkleRoot)
+}
+
+// TaprootMerkleRoot returns the Merkle root of the script tree containing
+// the leaf with the given hash, computed by hashing it with the hashes of
+// the path, from the leaf to the root, as found in a control block.
+func TaprootMerkleRoot(tags TaprootTags, leafHash []byte, path [][]byte) ([]byte, error) {
+	if len(leafHash) != LenTaprootHash {
+		return nil, newError("TaprootMerkleRoot", 0, ErrorTaprootHashSize)
+	}
+	for i, hash := range path {
+		if len(hash) != LenTaprootHash {
+			return nil, newIndexError("TaprootMerkleRoot", i, ErrorTaprootHashSize)
+		}
+	}
+
+	root := leafHash
+	for _, hash := range path {
+		root = TapBranchHash(tags, root, hash)
+	}
+	return root, nil
+}
+
+// TaprootTweakPubkey computes the output key of a Taproot output from its
+// internal key and the Merkle root of its script tree, empty if there is
+// none. The parity is 1 if the tweaked point has odd Y, and it must be
+// committed to in the control block of a script path spend. The context
+// must be initialized for verification. The return code is 1 if the output
+// key was computed, 0 if the tweak was out of range.
+func TaprootTweakPubkey(
+	ctx *Context,
+	tags TaprootTags,
+	internalKey *XOnlyPublicKey,
+	merkleRoot []byte,
+) (int, *XOnlyPublicKey, int, error) {
+	if len(merkleRoot) != 0 && len(merkleRoot) != LenTaprootHash {
+		return 0, nil, 0, newError("TaprootTweakPubkey", 0, ErrorMerkleRootSize)
+	}
+	if internalKey == nil {
+		return 0, nil, 0, newError("TaprootTweakPubkey", 0, ErrorPublicKeyNil)
+	}
+
+	pk := internalKey.PublicKey()
+	result, err := EcPubKeyTweakAdd(ctx, pk, TapTweakHash(tags, internalKey, merkleRoot))
+	if err != nil {
+		return result, nil, 0, err
+	}
+	return XOnlyPubkeyFromPubkey(ctx, pk)
+}
+
+// TaprootTweakSeckey computes the secret key of the output key returned by
+// TaprootTweakPubkey for the internal key of the given secret key. The
+// secret key is negated before being tweaked if its public key has odd Y,
+// since the internal key is always the point with even Y. The result can be
+// used with SchnorrSign to spend the output through the key path. The
+// context must be initialized for signing. The return code is 1 if the
+// secret key was computed, 0 if the secret key or the tweak were invalid.
+func TaprootTweakSeckey(ctx *Context, tags TaprootTags, seckey []byte, merkleRoot []byte) (int, []byte, error) {
+	if len(seckey) != LenPrivateKey {
+		return 0, nil, newError("TaprootTweakSeckey", 0, ErrorPrivateKeySize)
+	}
+	if len(merkleRoot) != 0 && len(merkleRoot) != LenTaprootHash {
+		return 0, nil, newError("TaprootTweakSeckey", 0, ErrorMerkleRootSize)
+	}
+
+	result, pk, err := EcPubkeyCreate(ctx, seckey)
+	if err != nil {
+		return result, nil, err
+	}
+	_, internalKey, parity, err := XOnlyPubkeyFromPubkey(ctx, pk)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	tweaked := make([]byte, LenPrivateKey)
+	copy(tweaked, seckey)
+	if parity == 1 {
+		if _, err := EcPrivKeyNegate(ctx, tweaked); err != nil {
+			return 0, nil, err
+		}
+	}
+	result, err = EcPrivKeyTweakAdd(ctx, tweaked, TapTweakHash(tags, internalKey, merkleRoot))
+	if err != nil {
+		zero(tweaked)
+		return result, nil, err
+	}
+	return result, tweaked, nil
+}
+
+// TaprootControlBlock builds the control block of a script path spend: a
+// byte holding the leaf version and the parity of the output key, followed
+// by the internal key and the hashes of the Merkle path of the leaf, from
+// the leaf to the root.
+func TaprootControlBlock(leafVersion byte, internalKey *XOnlyPublicKey, parity int, path [][]byte) ([]byte, error) {
+	if leafVersion&1 != 0 {
+		return nil, newError("TaprootControlBlock", 0, ErrorLeafVersion)
+	}
+	if parity != 0 && parity != 1 {
+		return nil, newError("TaprootControlBlock", 0, ErrorOutputKeyParity)
+	}
+	if internalKey == nil {
+		return nil, newError("TaprootControlBlock", 0, ErrorPublicKeyNil)
+	}
+	if len(path) > TaprootControlBlockMaxPath {
+		return nil, newError("TaprootControlBlock", 0, ErrorControlBlockPathSize)
+	}
+	for i, hash := range path {
+		if len(hash) != LenTaprootHash {
+			return nil, newIndexError("TaprootControlBlock", i, ErrorTaprootHashSize)
+		}
+	}
+
+	controlBlock := make([]byte, 0, 1+LenXOnlyPublicKey+len(path)*LenTaprootHash)
+	controlBlock = append(controlBlock, leafVersion|byte(parity))
+	controlBlock = append(controlBlock, internalKey.Serialize()...)
+	for _, hash := range path {
+		controlBlock = append(controlBlock, hash...)
+	}
+	return controlBlock, nil
+}
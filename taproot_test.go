@@ -0,0 +1,158 @@
+package secp256k1
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var taprootTags = map[string]TaprootTags{
+	"bitcoin":  BitcoinTaprootTags,
+	"elements": ElementsTaprootTags,
+}
+
+func TestTaprootTweak(t *testing.T) {
+	file, err := ioutil.ReadFile("testdata/taproot.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testVectorType struct {
+		Tags            string `json:"tags"`
+		InternalPrivKey string `json:"internalPrivKey"`
+		InternalPubKey  string `json:"internalPubKey"`
+		MerkleRoot      string `json:"merkleRoot"`
+		Tweak           string `json:"tweak"`
+		OutputKey       string `json:"outputKey"`
+		Parity          int    `json:"parity"`
+		TweakedPrivKey  string `json:"tweakedPrivKey"`
+	}
+	type testType struct {
+		Vectors []testVectorType `json:"tweak"`
+	}
+
+	var test testType
+	json.Unmarshal(file, &test)
+
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	for _, v := range test.Vectors {
+		tags := taprootTags[v.Tags]
+		privKey, _ := hex.DecodeString(v.InternalPrivKey)
+		internalPubKey, _ := hex.DecodeString(v.InternalPubKey)
+		merkleRoot, _ := hex.DecodeString(v.MerkleRoot)
+
+		_, internalKey, err := XOnlyPubkeyParse(ctx, internalPubKey)
+		assert.NoError(t, err)
+		assert.Equal(t, v.Tweak, hex.EncodeToString(TapTweakHash(tags, internalKey, merkleRoot)))
+
+		_, outputKey, parity, err := TaprootTweakPubkey(ctx, tags, internalKey, merkleRoot)
+		assert.NoError(t, err)
+		assert.Equal(t, v.OutputKey, hex.EncodeToString(outputKey.Serialize()))
+		assert.Equal(t, v.Parity, parity)
+
+		_, tweaked, err := TaprootTweakSeckey(ctx, tags, privKey, merkleRoot)
+		assert.NoError(t, err)
+		assert.Equal(t, v.TweakedPrivKey, hex.EncodeToString(tweaked))
+
+		// the tweaked secret key signs for the output key
+		msg := make([]byte, 32)
+		_, sig, _ := SchnorrSign(ctx, msg, tweaked, nil)
+		result, err := SchnorrVerify(ctx, sig, msg, outputKey)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result)
+	}
+}
+
+func TestTaprootControlBlock(t *testing.T) {
+	file, err := ioutil.ReadFile("testdata/taproot.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type testVectorType struct {
+		Tags           string   `json:"tags"`
+		InternalPubKey string   `json:"internalPubKey"`
+		LeafVersion    byte     `json:"leafVersion"`
+		Script         string   `json:"script"`
+		LeafHash       string   `json:"leafHash"`
+		Path           []string `json:"path"`
+		MerkleRoot     string   `json:"merkleRoot"`
+		OutputKey      string   `json:"outputKey"`
+		ControlBlock   string   `json:"controlBlock"`
+	}
+	type testType struct {
+		Vectors []testVectorType `json:"controlBlock"`
+	}
+
+	var test testType
+	json.Unmarshal(file, &test)
+
+	ctx, _ := ContextCreate(ContextVerify)
+	defer ContextDestroy(ctx)
+
+	for _, v := range test.Vectors {
+		tags := taprootTags[v.Tags]
+		internalPubKey, _ := hex.DecodeString(v.InternalPubKey)
+		script, _ := hex.DecodeString(v.Script)
+		path := make([][]byte, 0, len(v.Path))
+		for _, p := range v.Path {
+			hash, _ := hex.DecodeString(p)
+			path = append(path, hash)
+		}
+
+		leafHash := TapLeafHash(tags, v.LeafVersion, script)
+		assert.Equal(t, v.LeafHash, hex.EncodeToString(leafHash))
+
+		merkleRoot, err := TaprootMerkleRoot(tags, leafHash, path)
+		assert.NoError(t, err)
+		assert.Equal(t, v.MerkleRoot, hex.EncodeToString(merkleRoot))
+
+		_, internalKey, _ := XOnlyPubkeyParse(ctx, internalPubKey)
+		_, outputKey, parity, err := TaprootTweakPubkey(ctx, tags, internalKey, merkleRoot)
+		assert.NoError(t, err)
+		assert.Equal(t, v.OutputKey, hex.EncodeToString(outputKey.Serialize()))
+
+		controlBlock, err := TaprootControlBlock(v.LeafVersion, internalKey, parity, path)
+		assert.NoError(t, err)
+		assert.Equal(t, v.ControlBlock, hex.EncodeToString(controlBlock))
+	}
+}
+
+func TestTaprootInvalidInput(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	seckey := make([]byte, 32)
+	seckey[31] = 1
+	_, pk, _ := EcPubkeyCreate(ctx, seckey)
+	_, internalKey, _, _ := XOnlyPubkeyFromPubkey(ctx, pk)
+
+	_, _, _, err := TaprootTweakPubkey(ctx, BitcoinTaprootTags, internalKey, make([]byte, 31))
+	assert.True(t, errors.Is(err, ErrorMerkleRootSize))
+	_, _, err = TaprootTweakSeckey(ctx, BitcoinTaprootTags, seckey, make([]byte, 33))
+	assert.True(t, errors.Is(err, ErrorMerkleRootSize))
+
+	_, err = TaprootMerkleRoot(BitcoinTaprootTags, make([]byte, 32), [][]byte{make([]byte, 32), {}})
+	var e *Error
+	assert.True(t, errors.As(err, &e))
+	assert.Equal(t, 1, e.Index)
+	assert.True(t, errors.Is(err, ErrorTaprootHashSize))
+
+	_, err = TaprootControlBlock(0xc1, internalKey, 0, nil)
+	assert.True(t, errors.Is(err, ErrorLeafVersion))
+	_, err = TaprootControlBlock(TaprootLeafTapscript, internalKey, 2, nil)
+	assert.True(t, errors.Is(err, ErrorOutputKeyParity))
+	_, err = TaprootControlBlock(TaprootLeafTapscript, internalKey, 0, make([][]byte, TaprootControlBlockMaxPath+1))
+	assert.True(t, errors.Is(err, ErrorControlBlockPathSize))
+
+	// branches do not depend on the order of the children
+	a, b := TaggedHash("a"), TaggedHash("b")
+	assert.Equal(t, TapBranchHash(ElementsTaprootTags, a, b), TapBranchHash(ElementsTaprootTags, b, a))
+	assert.NotEqual(t, TapBranchHash(ElementsTaprootTags, a, b), TapBranchHash(BitcoinTaprootTags, a, b))
+}
@@ -0,0 +1,236 @@
+package secp256k1
+
+/*
+#include "include/secp256k1_whitelist.h"
+#cgo CFLAGS: -I${SRCDIR}/secp256k1-zkp -I${SRCDIR}/secp256k1-zkp/src
+*/
+import "C"
+
+import (
+	"errors"
+	"runtime"
+)
+
+const (
+	// WhitelistMaxKeys is the maximum number of key pairs of a whitelist
+	// signature. The library accepts SECP256K1_WHITELIST_MAX_N_KEYS, 256,
+	// when signing, but its serialization encodes the number of keys in a
+	// single byte and parsing rejects 256, so a signature of 256 keys could
+	// neither be serialized nor parsed back, and it is refused here.
+	WhitelistMaxKeys = 255
+
+	// Length of elements byte representations
+	LenMaxWhitelistSignature int = 1 + 32*(1+WhitelistMaxKeys)
+)
+
+var (
+	ErrorWhitelistKeyCount           = errors.New("number of online and offline public keys differ or are out of range")
+	ErrorWhitelistIndex              = errors.New("signer index out of range")
+	ErrorWhitelistSignatureParse     = errors.New("unable to parse the whitelist signature")
+	ErrorWhitelistSignatureNil       = errors.New("whitelist signature is nil")
+	ErrorWhitelistSignatureCreate    = errors.New("unable to create the whitelist signature")
+	ErrorWhitelistSignatureSerialize = errors.New("unable to serialize the whitelist signature")
+)
+
+// WhitelistSignature wraps a *secp256k1_whitelist_signature, the ring
+// signature used by Elements to prove that a key, usually the destination of
+// a peg-out, is whitelisted by one of the members of a list of online and
+// offline public key pairs, the PAK list, without revealing which one.
+type WhitelistSignature struct {
+	sig *C.secp256k1_whitelist_signature
+}
+
+func newWhitelistSignature() *WhitelistSignature {
+	return &WhitelistSignature{
+		sig: &C.secp256k1_whitelist_signature{},
+	}
+}
+
+// whitelistKeys checks the online and offline public keys and returns them
+// copied into arrays of secp256k1_pubkey, as expected by the library.
+func whitelistKeys(op string, onlinePubkeys, offlinePubkeys []*PublicKey) ([]C.secp256k1_pubkey, []C.secp256k1_pubkey, error) {
+	n := len(onlinePubkeys)
+	if n < 1 || n > WhitelistMaxKeys || len(offlinePubkeys) != n {
+		return nil, nil, newError(op, 0, ErrorWhitelistKeyCount)
+	}
+	online := make([]C.secp256k1_pubkey, n)
+	offline := make([]C.secp256k1_pubkey, n)
+	for i := 0; i < n; i++ {
+		if onlinePubkeys[i] == nil || offlinePubkeys[i] == nil {
+			return nil, nil, newIndexError(op, i, ErrorPublicKeyNil)
+		}
+		online[i] = *onlinePubkeys[i].pk
+		offline[i] = *offlinePubkeys[i].pk
+	}
+	return online, offline, nil
+}
+
+// WhitelistSign creates a whitelist signature of subPubkey, the key to be
+// whitelisted, by the signer at index in the lists of online and offline
+// public keys, which must have the same length. The onlineSeckey is the
+// secret key of the online public key of the signer, while summedSeckey is
+// the secret key of its offline public key tweaked by adding the secret key
+// of subPubkey, see EcPrivKeyTweakAdd. The context must be initialized for
+// signing and verification. The return code is 1 if the signature was
+// created, 0 otherwise.
+func WhitelistSign(
+	ctx *Context,
+	onlinePubkeys []*PublicKey,
+	offlinePubkeys []*PublicKey,
+	subPubkey *PublicKey,
+	onlineSeckey []byte,
+	summedSeckey []byte,
+	index int,
+) (int, *WhitelistSignature, error) {
+	online, offline, err := whitelistKeys("WhitelistSign", onlinePubkeys, offlinePubkeys)
+	if err != nil {
+		return 0, nil, err
+	}
+	if subPubkey == nil {
+		return 0, nil, newError("WhitelistSign", 0, ErrorPublicKeyNil)
+	}
+	if len(onlineSeckey) != LenPrivateKey || len(summedSeckey) != LenPrivateKey {
+		return 0, nil, newError("WhitelistSign", 0, ErrorPrivateKeySize)
+	}
+	if index < 0 || index >= len(online) {
+		return 0, nil, newError("WhitelistSign", 0, ErrorWhitelistIndex)
+	}
+
+	if err := ctx.require(ContextBoth); err != nil {
+		return 0, nil, newError("WhitelistSign", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newWhitelistSignature()
+	result, err := call("secp256k1_whitelist_sign", func() C.int {
+		return C.secp256k1_whitelist_sign(
+			ctx.ctx,
+			sig.sig,
+			&online[0],
+			&offline[0],
+			C.size_t(len(online)),
+			subPubkey.pk,
+			cBuf(onlineSeckey),
+			cBuf(summedSeckey),
+			C.size_t(index),
+			nil,
+			nil)
+	})
+	if err != nil {
+		return 0, nil, newError("WhitelistSign", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("WhitelistSign", result, ErrorWhitelistSignatureCreate)
+	}
+	return result, sig, nil
+}
+
+// WhitelistVerify verifies a whitelist signature of subPubkey against the
+// lists of online and offline public keys, which must have the same length.
+// The context must be initialized for verification. The return code is 1
+// for a correct signature, 0 for an incorrect signature, in which case no
+// error is returned.
+func WhitelistVerify(
+	ctx *Context,
+	sig *WhitelistSignature,
+	onlinePubkeys []*PublicKey,
+	offlinePubkeys []*PublicKey,
+	subPubkey *PublicKey,
+) (int, error) {
+	if sig == nil {
+		return 0, newError("WhitelistVerify", 0, ErrorWhitelistSignatureNil)
+	}
+	online, offline, err := whitelistKeys("WhitelistVerify", onlinePubkeys, offlinePubkeys)
+	if err != nil {
+		return 0, err
+	}
+	if subPubkey == nil {
+		return 0, newError("WhitelistVerify", 0, ErrorPublicKeyNil)
+	}
+
+	if err := ctx.require(ContextVerify); err != nil {
+		return 0, newError("WhitelistVerify", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	result, err := call("secp256k1_whitelist_verify", func() C.int {
+		return C.secp256k1_whitelist_verify(
+			ctx.ctx,
+			sig.sig,
+			&online[0],
+			&offline[0],
+			C.size_t(len(online)),
+			subPubkey.pk)
+	})
+	if err != nil {
+		return 0, newError("WhitelistVerify", 0, err)
+	}
+	return result, nil
+}
+
+// WhitelistSignatureParse parses a whitelist signature: a byte holding the
+// number of keys n, followed by 32*(n+1) bytes. The return code is 1 if the
+// signature was parsed, 0 if its length does not match the number of keys.
+func WhitelistSignatureParse(ctx *Context, input []byte) (int, *WhitelistSignature, error) {
+	if len(input) == 0 {
+		return 0, nil, newError("WhitelistSignatureParse", 0, ErrorWhitelistSignatureParse)
+	}
+
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("WhitelistSignatureParse", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	sig := newWhitelistSignature()
+	result, err := call("secp256k1_whitelist_signature_parse", func() C.int {
+		return C.secp256k1_whitelist_signature_parse(ctx.ctx, sig.sig, cBuf(input), C.size_t(len(input)))
+	})
+	if err != nil {
+		return 0, nil, newError("WhitelistSignatureParse", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("WhitelistSignatureParse", result, ErrorWhitelistSignatureParse)
+	}
+	return result, sig, nil
+}
+
+// WhitelistSignatureSerialize serializes a whitelist signature into
+// 1+32*(n+1) bytes, n being its number of keys. The return code is 1 if the
+// signature was serialized, 0 otherwise.
+func WhitelistSignatureSerialize(ctx *Context, sig *WhitelistSignature) (int, []byte, error) {
+	if sig == nil {
+		return 0, nil, newError("WhitelistSignatureSerialize", 0, ErrorWhitelistSignatureNil)
+	}
+	if err := ctx.check(); err != nil {
+		return 0, nil, newError("WhitelistSignatureSerialize", 0, err)
+	}
+	defer runtime.KeepAlive(ctx)
+
+	output := make([]byte, LenMaxWhitelistSignature)
+	outputLen := C.size_t(len(output))
+	result, err := call("secp256k1_whitelist_signature_serialize", func() C.int {
+		return C.secp256k1_whitelist_signature_serialize(ctx.ctx, cBuf(output), &outputLen, sig.sig)
+	})
+	if err != nil {
+		return 0, nil, newError("WhitelistSignatureSerialize", 0, err)
+	}
+	if result != 1 {
+		return result, nil, newError("WhitelistSignatureSerialize", result, ErrorWhitelistSignatureSerialize)
+	}
+	return result, output[:outputLen], nil
+}
+
+// Serialize returns the serialized whitelist signature
+func (sig *WhitelistSignature) Serialize() []byte {
+	_, bytes, _ := WhitelistSignatureSerialize(SharedContext(ContextNone), sig)
+	return bytes
+}
+
+// NKeys returns the number of key pairs the signature was created for, 0 if
+// the signature is nil
+func (sig *WhitelistSignature) NKeys() int {
+	if sig == nil {
+		return 0
+	}
+	return int(C.secp256k1_whitelist_signature_n_keys(sig.sig))
+}
@@ -0,0 +1,161 @@
+package secp256k1
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type whitelistTestKeys struct {
+	onlineSeckeys  [][]byte
+	offlineSeckeys [][]byte
+	online         []*PublicKey
+	offline        []*PublicKey
+}
+
+func newWhitelistTestKeys(t *testing.T, ctx *Context, n int) *whitelistTestKeys {
+	keys := &whitelistTestKeys{}
+	for i := 0; i < n; i++ {
+		for _, list := range []*[][]byte{&keys.onlineSeckeys, &keys.offlineSeckeys} {
+			seckey := make([]byte, 32)
+			rand.Read(seckey)
+			*list = append(*list, seckey)
+		}
+		_, online, err := EcPubkeyCreate(ctx, keys.onlineSeckeys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, offline, err := EcPubkeyCreate(ctx, keys.offlineSeckeys[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys.online = append(keys.online, online)
+		keys.offline = append(keys.offline, offline)
+	}
+	return keys
+}
+
+// sign creates the whitelist signature of the key of subSeckey by the
+// signer at index
+func (keys *whitelistTestKeys) sign(t *testing.T, ctx *Context, subSeckey []byte, index int) (*PublicKey, *WhitelistSignature) {
+	_, subPubkey, _ := EcPubkeyCreate(ctx, subSeckey)
+	summed := make([]byte, 32)
+	copy(summed, keys.offlineSeckeys[index])
+	EcPrivKeyTweakAdd(ctx, summed, subSeckey)
+
+	_, sig, err := WhitelistSign(ctx, keys.online, keys.offline, subPubkey, keys.onlineSeckeys[index], summed, index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return subPubkey, sig
+}
+
+func TestWhitelistSignAndVerify(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	for _, n := range []int{1, 3, 10} {
+		keys := newWhitelistTestKeys(t, ctx, n)
+		subSeckey := make([]byte, 32)
+		rand.Read(subSeckey)
+
+		for index := 0; index < n; index++ {
+			subPubkey, sig := keys.sign(t, ctx, subSeckey, index)
+			assert.Equal(t, n, sig.NKeys())
+
+			serialized := sig.Serialize()
+			assert.Len(t, serialized, 1+32*(n+1))
+			_, parsed, err := WhitelistSignatureParse(ctx, serialized)
+			assert.NoError(t, err)
+			assert.Equal(t, serialized, parsed.Serialize())
+
+			result, err := WhitelistVerify(ctx, parsed, keys.online, keys.offline, subPubkey)
+			assert.NoError(t, err)
+			assert.Equal(t, 1, result)
+
+			// the signature whitelists that key only
+			_, other, _ := EcPubkeyCreate(ctx, keys.onlineSeckeys[0])
+			result, err = WhitelistVerify(ctx, parsed, keys.online, keys.offline, other)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, result)
+
+			// and only for that list of keys
+			result, err = WhitelistVerify(ctx, parsed, keys.offline, keys.online, subPubkey)
+			assert.NoError(t, err)
+			assert.Equal(t, 0, result)
+		}
+	}
+}
+
+func TestWhitelistInvalidInput(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	keys := newWhitelistTestKeys(t, ctx, 2)
+	seckey := keys.onlineSeckeys[0]
+	subPubkey := keys.online[1]
+
+	_, _, err := WhitelistSign(ctx, keys.online, keys.offline[:1], subPubkey, seckey, seckey, 0)
+	assert.True(t, errors.Is(err, ErrorWhitelistKeyCount))
+	_, _, err = WhitelistSign(ctx, nil, nil, subPubkey, seckey, seckey, 0)
+	assert.True(t, errors.Is(err, ErrorWhitelistKeyCount))
+	_, _, err = WhitelistSign(ctx, keys.online, keys.offline, subPubkey, seckey, seckey, 2)
+	assert.True(t, errors.Is(err, ErrorWhitelistIndex))
+	_, _, err = WhitelistSign(ctx, keys.online, []*PublicKey{keys.offline[0], nil}, subPubkey, seckey, seckey, 0)
+	assert.True(t, errors.Is(err, ErrorPublicKeyNil))
+
+	verifyCtx, _ := ContextCreate(ContextVerify)
+	defer ContextDestroy(verifyCtx)
+	_, _, err = WhitelistSign(verifyCtx, keys.online, keys.offline, subPubkey, seckey, seckey, 0)
+	assert.True(t, errors.Is(err, ErrContextCapability))
+
+	_, sig := keys.sign(t, ctx, seckey, 1)
+	_, err = WhitelistVerify(ctx, sig, keys.online[:1], keys.offline[:1], subPubkey)
+	assert.NoError(t, err)
+	_, err = WhitelistVerify(ctx, nil, keys.online, keys.offline, subPubkey)
+	assert.True(t, errors.Is(err, ErrorWhitelistSignatureNil))
+
+	for _, input := range [][]byte{nil, {1}, make([]byte, 64), append([]byte{2}, make([]byte, 64)...)} {
+		_, _, err = WhitelistSignatureParse(ctx, input)
+		assert.True(t, errors.Is(err, ErrorWhitelistSignatureParse))
+	}
+}
+
+func TestWhitelistMaxKeys(t *testing.T) {
+	ctx, _ := ContextCreate(ContextBoth)
+	defer ContextDestroy(ctx)
+
+	keys := newWhitelistTestKeys(t, ctx, WhitelistMaxKeys+1)
+	subSeckey := make([]byte, 32)
+	rand.Read(subSeckey)
+	_, subPubkey, _ := EcPubkeyCreate(ctx, subSeckey)
+	seckey := keys.onlineSeckeys[0]
+
+	_, _, err := WhitelistSign(ctx, keys.online, keys.offline, subPubkey, seckey, seckey, 0)
+	assert.True(t, errors.Is(err, ErrorWhitelistKeyCount))
+
+	keys.online = keys.online[:WhitelistMaxKeys]
+	keys.offline = keys.offline[:WhitelistMaxKeys]
+	_, sig := keys.sign(t, ctx, subSeckey, WhitelistMaxKeys-1)
+	assert.Equal(t, WhitelistMaxKeys, sig.NKeys())
+
+	serialized := sig.Serialize()
+	assert.Len(t, serialized, LenMaxWhitelistSignature)
+	_, parsed, err := WhitelistSignatureParse(ctx, serialized)
+	assert.NoError(t, err)
+	result, err := WhitelistVerify(ctx, parsed, keys.online, keys.offline, subPubkey)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, result)
+
+	// nor verified against one key more
+	_, err = WhitelistVerify(ctx, parsed, append(keys.online, subPubkey), append(keys.offline, subPubkey), subPubkey)
+	assert.True(t, errors.Is(err, ErrorWhitelistKeyCount))
+
+	// the serialization of 256 keys, whose count wraps to 0 in its first
+	// byte, does not parse
+	tooMany := append([]byte{0}, make([]byte, 32*(WhitelistMaxKeys+2))...)
+	_, _, err = WhitelistSignatureParse(ctx, tooMany)
+	assert.True(t, errors.Is(err, ErrorWhitelistSignatureParse))
+}